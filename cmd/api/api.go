@@ -2,15 +2,20 @@ package api
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 
 	"github.com/alice890308/blog-server/modules/api/dao"
 	"github.com/alice890308/blog-server/modules/api/pb"
 	"github.com/alice890308/blog-server/modules/api/service"
+	"github.com/alice890308/blog-server/modules/api/worker"
 	"github.com/alice890308/blog-server/pkg/authkit"
 	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mailkit"
 	"github.com/alice890308/blog-server/pkg/mongokit"
+	"github.com/alice890308/blog-server/pkg/passwordkit"
+	"github.com/alice890308/blog-server/pkg/rediskit"
 	"github.com/alice890308/blog-server/pkg/runkit"
 	flags "github.com/jessevdk/go-flags"
 	"github.com/spf13/cobra"
@@ -27,11 +32,22 @@ func newAPICommand() *cobra.Command {
 }
 
 type APIArgs struct {
-	GRPCAddr              string `long:"grpc_addr" env:"RCPC_ADDR" default:":8081"`
-	runkit.GracefulConfig `group:"graceful" namespace:"graceful" env-namespace:"GRACEFUL"`
-	logkit.LoggerConfig   `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
-	mongokit.MongoConfig  `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
-	authkit.JWTConfig     `group:"jwt" namespace:"jwt" env-namespace:"JWT"`
+	GRPCAddr                        string `long:"grpc_addr" env:"RCPC_ADDR" default:":8081"`
+	runkit.GracefulConfig           `group:"graceful" namespace:"graceful" env-namespace:"GRACEFUL"`
+	logkit.LoggerConfig             `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	mongokit.MongoConfig            `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
+	authkit.JWTConfig               `group:"jwt" namespace:"jwt" env-namespace:"JWT"`
+	authkit.OIDCConfig              `group:"oidc" namespace:"oidc" env-namespace:"OIDC"`
+	authkit.PolicyConfig            `group:"auth" namespace:"auth" env-namespace:"AUTH"`
+	mailkit.MailerConfig            `group:"mail" namespace:"mail" env-namespace:"MAIL"`
+	passwordkit.HasherConfig        `group:"password" namespace:"password" env-namespace:"PASSWORD"`
+	service.PasswordResetConfig     `group:"password_reset" namespace:"password_reset" env-namespace:"PASSWORD_RESET"`
+	service.EmailVerificationConfig `group:"email_verification" namespace:"email_verification" env-namespace:"EMAIL_VERIFICATION"`
+	service.LoginLimitConfig        `group:"login" namespace:"login" env-namespace:"LOGIN"`
+	service.TOTPConfig              `group:"totp" namespace:"totp" env-namespace:"TOTP"`
+	rediskit.RedisConfig            `group:"redis" namespace:"redis" env-namespace:"REDIS"`
+	worker.ViewConfig               `group:"view" namespace:"view" env-namespace:"VIEW"`
+	worker.PublisherConfig          `group:"publisher" namespace:"publisher" env-namespace:"PUBLISHER"`
 }
 
 func runAPI(_ *cobra.Command, _ []string) error {
@@ -63,8 +79,79 @@ func runAPI(_ *cobra.Command, _ []string) error {
 	}
 
 	userDAO := dao.NewMongoUserDAO(mongoClient.Database().Collection("users"))
+	if err := userDAO.CreateIndex(ctx); err != nil {
+		if errors.Is(err, dao.ErrDuplicateUsers) {
+			// legacy data the operator has to fix, not a failure of the server
+			logger.Error("failed to create user index, the existing users must be migrated first", zap.Error(err))
+			return err
+		}
+		logger.Fatal("failed to create user index!", zap.Error(err))
+	}
+
+	commentDAO := dao.NewMongoCommentDAO(mongoClient.Database().Collection("comments"))
+	if err := commentDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create comment index!", zap.Error(err))
+	}
+
+	likeDAO := dao.NewMongoLikeDAO(mongoClient.Database().Collection("likes"))
+	if err := likeDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create like index!", zap.Error(err))
+	}
+
+	revisionDAO := dao.NewMongoRevisionDAO(mongoClient.Database().Collection("revisions"))
+	if err := revisionDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create revision index!", zap.Error(err))
+	}
+
+	followDAO := dao.NewMongoFollowDAO(mongoClient.Database().Collection("follows"))
+	if err := followDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create follow index!", zap.Error(err))
+	}
+
+	sessionDAO := dao.NewMongoSessionDAO(mongoClient.Database().Collection("sessions"))
+	if err := sessionDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create session index!", zap.Error(err))
+	}
+
+	passwordResetDAO := dao.NewMongoPasswordResetDAO(mongoClient.Database().Collection("password_resets"))
+	if err := passwordResetDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create password reset index!", zap.Error(err))
+	}
+
+	identityDAO := dao.NewMongoIdentityDAO(mongoClient.Database().Collection("identities"))
+	if err := identityDAO.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create identity index!", zap.Error(err))
+	}
+
+	accessTokenStore := authkit.NewMongoAccessTokenStore(mongoClient.Database().Collection("access_tokens"))
+	if err := accessTokenStore.CreateIndex(ctx); err != nil {
+		logger.Fatal("failed to create access token index!", zap.Error(err))
+	}
+
+	redisClient := rediskit.NewRedisClient(ctx, &args.RedisConfig)
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			logger.Fatal("failed to close redis client", zap.Error(err))
+		}
+	}()
+
+	viewDAO := dao.NewRedisViewDAO(redisClient.Client, args.ViewConfig.Window)
+	loginAttemptDAO := dao.NewRedisLoginAttemptDAO(redisClient.Client)
+	viewFlusher := worker.NewViewFlusher(viewDAO, postDAO, &args.ViewConfig, logger)
+	postPublisher := worker.NewPostPublisher(postDAO, &args.PublisherConfig, logger)
+
 	jwtManager := authkit.NewJWTManager(ctx, &args.JWTConfig)
-	svc := service.NewService(postDAO, userDAO, jwtManager)
+	denylist := authkit.NewRedisDenylist(redisClient.Client, jwtManager.TokenDuration())
+	mailer := mailkit.NewMailer(ctx, &args.MailerConfig)
+	hasher, err := passwordkit.NewHasher(&args.HasherConfig)
+	if err != nil {
+		logger.Fatal("failed to create password hasher", zap.Error(err))
+	}
+	svc := service.NewService(
+		postDAO, userDAO, commentDAO, likeDAO, viewDAO, revisionDAO, followDAO, sessionDAO, passwordResetDAO, identityDAO,
+		jwtManager, denylist, mailer, hasher, loginAttemptDAO, accessTokenStore, authkit.NewOIDCProvider(&args.OIDCConfig),
+		&args.PasswordResetConfig, &args.EmailVerificationConfig, &args.LoginLimitConfig, &args.TOTPConfig, logger,
+	)
 
 	logger.Info("listen to gRPC addr", zap.String("grpc_addr", args.GRPCAddr))
 	lis, err := net.Listen("tcp", args.GRPCAddr)
@@ -77,9 +164,17 @@ func runAPI(_ *cobra.Command, _ []string) error {
 		}
 	}()
 
-	auth := authkit.NewAuthService(jwtManager)
+	policy, err := authkit.NewPolicy(&args.PolicyConfig)
+	if err != nil {
+		logger.Fatal("failed to load auth policy", zap.Error(err))
+	}
+	auth := authkit.NewAuthService(jwtManager, denylist, policy, accessTokenStore)
 
-	return runkit.GracefulRun(serveGRPC(lis, svc, logger, grpc.UnaryInterceptor(auth.UnaryServerInterceptor())), &args.GracefulConfig)
+	return runkit.GracefulRun(runkit.Group(
+		serveGRPC(lis, svc, logger, grpc.UnaryInterceptor(auth.UnaryServerInterceptor())),
+		viewFlusher.Run,
+		postPublisher.Run,
+	), &args.GracefulConfig)
 }
 
 func serveGRPC(lis net.Listener, svc *service.Service, logger *logkit.Logger, opt ...grpc.ServerOption) runkit.GracefulRunFunc {
@@ -87,6 +182,7 @@ func serveGRPC(lis net.Listener, svc *service.Service, logger *logkit.Logger, op
 	pb.RegisterPostServer(grpcServer, svc)
 	pb.RegisterUserServer(grpcServer, svc)
 	pb.RegisterSessionServer(grpcServer, svc)
+	pb.RegisterCommentServer(grpcServer, svc)
 
 	return func(ctx context.Context) error {
 		go func() {
@@ -6,7 +6,10 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/alice890308/blog-server/modules/api/feed"
+	"github.com/alice890308/blog-server/modules/api/oidc"
 	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
 	"github.com/alice890308/blog-server/pkg/grpckit"
 	"github.com/alice890308/blog-server/pkg/logkit"
 	"github.com/alice890308/blog-server/pkg/runkit"
@@ -30,6 +33,9 @@ type GatewayArgs struct {
 	grpckit.GrpcClientConnConfig `group:"grpc" namespace:"grpc" env-namespace:"GRPC"`
 	runkit.GracefulConfig        `group:"graceful" namespace:"graceful" env-namespace:"GRACEFUL"`
 	logkit.LoggerConfig          `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	feed.FeedConfig              `group:"feed" namespace:"feed" env-namespace:"FEED"`
+	authkit.KeyConfig            `group:"jwt" namespace:"jwt" env-namespace:"JWT"`
+	authkit.OIDCConfig           `group:"oidc" namespace:"oidc" env-namespace:"OIDC"`
 }
 
 func runGateway(_ *cobra.Command, _ []string) error {
@@ -65,14 +71,31 @@ func runGateway(_ *cobra.Command, _ []string) error {
 		}
 	}()
 
-	return runkit.GracefulRun(serveHTTP(lis, conn.ClientConn, logger), &args.GracefulConfig)
+	keys := authkit.NewKeySet(ctx, &args.KeyConfig)
+
+	return runkit.GracefulRun(serveHTTP(lis, conn.ClientConn, &args.FeedConfig, keys, &args.OIDCConfig, logger), &args.GracefulConfig)
 }
 
-func serveHTTP(lis net.Listener, conn *grpc.ClientConn, logger *logkit.Logger) runkit.GracefulRunFunc {
+func serveHTTP(
+	lis net.Listener,
+	conn *grpc.ClientConn,
+	feedConf *feed.FeedConfig,
+	keys *authkit.KeySet,
+	oidcConf *authkit.OIDCConfig,
+	logger *logkit.Logger,
+) runkit.GracefulRunFunc {
 	mux := runtime.NewServeMux()
 
+	root := http.NewServeMux()
+	root.Handle(feed.PathPrefix, feed.NewHandler(pb.NewPostClient(conn), feedConf, logger))
+	root.Handle(authkit.JWKSPath, authkit.NewJWKSHandler(keys))
+	if provider := authkit.NewOIDCProvider(oidcConf); provider != nil {
+		root.Handle(oidc.PathPrefix, oidc.NewHandler(provider, pb.NewSessionClient(conn), oidcConf, logger))
+	}
+	root.Handle("/", mux)
+
 	httpServer := &http.Server{
-		Handler: cors(mux),
+		Handler: cors(root),
 	}
 
 	return func(ctx context.Context) error {
@@ -85,6 +108,9 @@ func serveHTTP(lis net.Listener, conn *grpc.ClientConn, logger *logkit.Logger) r
 		if err := pb.RegisterSessionHandler(ctx, mux, conn); err != nil {
 			logger.Fatal("failed to register session handler to HTTP server", zap.Error(err))
 		}
+		if err := pb.RegisterCommentHandler(ctx, mux, conn); err != nil {
+			logger.Fatal("failed to register comment handler to HTTP server", zap.Error(err))
+		}
 
 		go func() {
 			if err := httpServer.Serve(lis); err != nil {
@@ -10,5 +10,7 @@ func NewAPICommand() *cobra.Command {
 
 	cmd.AddCommand(newAPICommand())
 	cmd.AddCommand(newGatewayCommand())
+	cmd.AddCommand(newRoleCommand())
+	cmd.AddCommand(newMigrateUsersCommand())
 	return cmd
 }
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mongokit"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// newMigrateUsersCommand prepares the users stored before the unique account and email indexes, which the api
+// server can not start without, and before the prefix search, which does not find them until then.
+func newMigrateUsersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-users [--dry_run]",
+		Short: "lowercases the emails of the users, makes them searchable by prefix and reports the users sharing an account or an email",
+		// the flags are parsed by go-flags along with the configs
+		DisableFlagParsing: true,
+		RunE:               runMigrateUsers,
+	}
+}
+
+type MigrateUsersArgs struct {
+	DryRun               bool `long:"dry_run" description:"report the changes and conflicts without changing anything"`
+	logkit.LoggerConfig  `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	mongokit.MongoConfig `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
+}
+
+func runMigrateUsers(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	var args MigrateUsersArgs
+	if _, err := flags.NewParser(&args, flags.Default).Parse(); err != nil {
+		log.Fatal("failed to parse flag", err.Error())
+	}
+
+	logger := logkit.NewLogger(&args.LoggerConfig)
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	ctx = logger.WithContext(ctx)
+
+	mongoClient := mongokit.NewMongoClient(ctx, &args.MongoConfig)
+	defer func() {
+		if err := mongoClient.Close(); err != nil {
+			logger.Fatal("failed to close mongo client", zap.Error(err))
+		}
+	}()
+
+	userDAO := dao.NewMongoUserDAO(mongoClient.Database().Collection("users"))
+	migration, err := userDAO.Migrate(ctx, args.DryRun)
+	if err != nil {
+		return err
+	}
+
+	normalized := "lowercased email"
+	if args.DryRun {
+		normalized = "would lowercase email"
+	}
+	for _, user := range migration.Normalized {
+		logger.Info(normalized,
+			zap.String("user_id", user.ID.Hex()),
+			zap.String("user_account", user.Account),
+			zap.String("email", user.Email),
+			zap.String("lowercased", strings.ToLower(strings.TrimSpace(user.Email))),
+		)
+	}
+	searchable := "made searchable by prefix"
+	if args.DryRun {
+		searchable = "would make searchable by prefix"
+	}
+	for _, user := range migration.Searchable {
+		logger.Info(searchable, zap.String("user_id", user.ID.Hex()), zap.String("user_account", user.Account))
+	}
+	for _, users := range migration.AccountConflicts {
+		logger.Warn("users share an account", zap.String("user_account", users[0].Account), zap.Strings("user_ids", userIDs(users)))
+	}
+	for _, users := range migration.EmailConflicts {
+		logger.Warn("users share an email",
+			zap.String("email", strings.ToLower(strings.TrimSpace(users[0].Email))),
+			zap.Strings("user_ids", userIDs(users)),
+			zap.Strings("user_accounts", userAccounts(users)),
+		)
+	}
+
+	logger.Info("user migration done",
+		zap.Bool("dry_run", args.DryRun),
+		zap.Int("lowercased", len(migration.Normalized)),
+		zap.Int("searchable", len(migration.Searchable)),
+		zap.Int("account_conflicts", len(migration.AccountConflicts)),
+		zap.Int("email_conflicts", len(migration.EmailConflicts)),
+	)
+
+	if conflicts := len(migration.AccountConflicts) + len(migration.EmailConflicts); conflicts > 0 {
+		// the unique indexes can only be created once every conflict is resolved by hand
+		return fmt.Errorf("%d conflicts must be resolved by changing or clearing the account or email of all but one user", conflicts)
+	}
+
+	return nil
+}
+
+func userIDs(users []*dao.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID.Hex())
+	}
+
+	return ids
+}
+
+func userAccounts(users []*dao.User) []string {
+	accounts := make([]string, 0, len(users))
+	for _, user := range users {
+		accounts = append(accounts, user.Account)
+	}
+
+	return accounts
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mongokit"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// newRoleCommand sets the role of a user from the command line, it is how the first admin is created.
+func newRoleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "role <user_account> <reader|author|editor|admin>",
+		Short: "sets the role of a user",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runRole,
+	}
+}
+
+type RoleArgs struct {
+	logkit.LoggerConfig  `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	mongokit.MongoConfig `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
+}
+
+func runRole(_ *cobra.Command, positional []string) error {
+	ctx := context.Background()
+
+	var args RoleArgs
+	if _, err := flags.NewParser(&args, flags.Default).Parse(); err != nil {
+		log.Fatal("failed to parse flag", err.Error())
+	}
+
+	account, role := positional[0], authkit.Role(positional[1])
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	logger := logkit.NewLogger(&args.LoggerConfig)
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	ctx = logger.WithContext(ctx)
+
+	mongoClient := mongokit.NewMongoClient(ctx, &args.MongoConfig)
+	defer func() {
+		if err := mongoClient.Close(); err != nil {
+			logger.Fatal("failed to close mongo client", zap.Error(err))
+		}
+	}()
+
+	userDAO := dao.NewMongoUserDAO(mongoClient.Database().Collection("users"))
+	user, err := userDAO.GetByUserAccount(ctx, account)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return fmt.Errorf("user %q not found", account)
+		}
+		return err
+	}
+
+	if err := userDAO.UpdateRole(ctx, user.ID, role); err != nil {
+		return err
+	}
+
+	// tokens carrying the old role stay valid until they expire
+	logger.Info("set user role", zap.String("user_account", account), zap.String("role", string(role)))
+
+	return nil
+}
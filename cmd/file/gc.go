@@ -0,0 +1,95 @@
+package file
+
+import (
+	"context"
+	"log"
+
+	"github.com/alice890308/blog-server/modules/file/dao"
+	"github.com/alice890308/blog-server/modules/file/worker"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mongokit"
+	"github.com/alice890308/blog-server/pkg/storagekit"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// newGCCommand collects the orphaned uploads once, or reports them with --dry_run.
+func newGCCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc [--dry_run]",
+		Short: "removes the uploaded files no post or user references",
+		// the flags are parsed by go-flags along with the configs
+		DisableFlagParsing: true,
+		RunE:               runGC,
+	}
+}
+
+type GCArgs struct {
+	DryRun                   bool `long:"dry_run" description:"report the files that would be collected without changing anything"`
+	logkit.LoggerConfig      `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	mongokit.MongoConfig     `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
+	storagekit.StorageConfig `group:"storage" namespace:"storage" env-namespace:"STORAGE"`
+	worker.GCConfig          `group:"gc" namespace:"gc" env-namespace:"GC"`
+}
+
+func runGC(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	var args GCArgs
+	if _, err := flags.NewParser(&args, flags.Default).Parse(); err != nil {
+		log.Fatal("failed to parse flag", err.Error())
+	}
+
+	logger := logkit.NewLogger(&args.LoggerConfig)
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	ctx = logger.WithContext(ctx)
+
+	mongoClient := mongokit.NewMongoClient(ctx, &args.MongoConfig)
+	defer func() {
+		if err := mongoClient.Close(); err != nil {
+			logger.Fatal("failed to close mongo client", zap.Error(err))
+		}
+	}()
+
+	collector := worker.NewGarbageCollector(
+		storagekit.NewStorage(ctx, &args.StorageConfig),
+		dao.NewMongoFileDAO(mongoClient.Database().Collection("files")),
+		dao.NewMongoUsageDAO(mongoClient.Database().Collection("file_usages")),
+		dao.NewMongoOrphanDAO(mongoClient.Database().Collection("file_orphans")),
+		newReferenceDAO(mongoClient),
+		&args.GCConfig,
+		logger,
+	)
+
+	report, err := collector.Collect(ctx, args.DryRun)
+	if err != nil {
+		return err
+	}
+
+	collected := "collected orphaned file"
+	if args.DryRun {
+		collected = "would collect orphaned file"
+	}
+	for _, orphan := range report.Collected {
+		logger.Info(collected, zap.String("key", orphan.Key), zap.Int64("size", orphan.Size), zap.Time("orphaned_at", orphan.OrphanedAT))
+	}
+	for _, orphan := range report.Pending {
+		logger.Info("orphaned file within grace period", zap.String("key", orphan.Key), zap.Int64("size", orphan.Size), zap.Time("orphaned_at", orphan.OrphanedAT))
+	}
+
+	logger.Info("garbage collection done",
+		zap.Bool("dry_run", args.DryRun),
+		zap.Bool("quarantine", args.GCConfig.Quarantine),
+		zap.Int("objects", report.Objects),
+		zap.Int("referenced", report.Referenced),
+		zap.Int("pending", len(report.Pending)),
+		zap.Int("collected", len(report.Collected)),
+		zap.Int("failed", report.Failed),
+	)
+
+	return nil
+}
@@ -2,31 +2,48 @@ package file
 
 import (
 	"context"
+	"errors"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"net/http"
 
+	"github.com/alice890308/blog-server/modules/file/dao"
 	"github.com/alice890308/blog-server/modules/file/middleware"
 	"github.com/alice890308/blog-server/modules/file/service"
+	"github.com/alice890308/blog-server/modules/file/worker"
 	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/imagekit"
 	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mongokit"
+	"github.com/alice890308/blog-server/pkg/rediskit"
+	"github.com/alice890308/blog-server/pkg/runkit"
+	"github.com/alice890308/blog-server/pkg/storagekit"
 	"github.com/gin-gonic/gin"
 	"github.com/jessevdk/go-flags"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 type APIArgs struct {
-	logkit.LoggerConfig `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
-	authkit.JWTConfig   `group:"jwt" namespace:"jwt" env-namespace:"JWT"`
+	logkit.LoggerConfig      `group:"logger" namespace:"logger" env-namespace:"LOGGER"`
+	authkit.JWTConfig        `group:"jwt" namespace:"jwt" env-namespace:"JWT"`
+	mongokit.MongoConfig     `group:"mongo" namespace:"mongo" env-namespace:"MONGO"`
+	rediskit.RedisConfig     `group:"redis" namespace:"redis" env-namespace:"REDIS"`
+	storagekit.StorageConfig `group:"storage" namespace:"storage" env-namespace:"STORAGE"`
+	service.UploadConfig     `group:"upload" namespace:"upload" env-namespace:"UPLOAD"`
+	imagekit.ImageConfig     `group:"image" namespace:"image" env-namespace:"IMAGE"`
+	worker.GCConfig          `group:"gc" namespace:"gc" env-namespace:"GC"`
+	runkit.GracefulConfig    `group:"graceful" namespace:"graceful" env-namespace:"GRACEFUL"`
 }
 
 func NewFileCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "file",
 		Short: "start file's service",
 		RunE:  runFile,
 	}
+
+	cmd.AddCommand(newGCCommand())
+	return cmd
 }
 
 func runFile(_ *cobra.Command, _ []string) error {
@@ -40,13 +57,44 @@ func runFile(_ *cobra.Command, _ []string) error {
 
 	ctx = logger.WithContext(ctx)
 	jwtManager := authkit.NewJWTManager(ctx, &args.JWTConfig)
-	svc := service.NewService(jwtManager)
+
+	mongoClient := mongokit.NewMongoClient(ctx, &args.MongoConfig)
+	defer func() {
+		if err := mongoClient.Close(); err != nil {
+			log.Fatal("failed to close mongo client", err.Error())
+		}
+	}()
+
+	redisClient := rediskit.NewRedisClient(ctx, &args.RedisConfig)
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Fatal("failed to close redis client", err.Error())
+		}
+	}()
+
+	accessTokenStore := authkit.NewMongoAccessTokenStore(mongoClient.Database().Collection("access_tokens"))
+	// the file service has no gRPC methods, its routes are checked against a rule of their own
+	authService := authkit.NewAuthService(jwtManager, authkit.NewRedisDenylist(redisClient.Client, jwtManager.TokenDuration()), nil, accessTokenStore)
+	usageDAO := dao.NewMongoUsageDAO(mongoClient.Database().Collection("file_usages"))
+	fileDAO := dao.NewMongoFileDAO(mongoClient.Database().Collection("files"))
+	if err := fileDAO.CreateIndex(ctx); err != nil {
+		log.Fatal("failed to create file index", err.Error())
+	}
+	referenceDAO := newReferenceDAO(mongoClient)
+	storage := storagekit.NewStorage(ctx, &args.StorageConfig)
+	svc := service.NewService(authService, usageDAO, fileDAO, referenceDAO, storage, &args.UploadConfig, &args.ImageConfig, logger)
 
 	router := gin.Default()
 
 	router.Use(middleware.CORS())
 
-	router.StaticFS("/static", gin.Dir("/static", false))
+	router.GET("/static/*key", func(c *gin.Context) {
+		svc.Download(c)
+	})
+
+	router.HEAD("/static/*key", func(c *gin.Context) {
+		svc.Download(c)
+	})
 
 	router.GET("/", func(c *gin.Context) {
 		svc.Status(c)
@@ -56,19 +104,68 @@ func runFile(_ *cobra.Command, _ []string) error {
 		svc.Upload(c)
 	})
 
-	err := router.Run(":8080")
-	if err != nil {
-		log.Fatal(err)
-	}
+	router.GET("/files", func(c *gin.Context) {
+		svc.ListFiles(c)
+	})
+
+	router.GET("/files/:id", func(c *gin.Context) {
+		svc.GetFile(c)
+	})
+
+	router.DELETE("/files/:id", func(c *gin.Context) {
+		svc.DeleteFile(c)
+	})
 
 	defer func() {
 		_ = logger.Sync()
 	}()
 
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
+	runs := []runkit.GracefulRunFunc{serveHTTP(":8080", router, logger)}
+	if args.GCConfig.Interval > 0 {
+		orphanDAO := dao.NewMongoOrphanDAO(mongoClient.Database().Collection("file_orphans"))
+		collector := worker.NewGarbageCollector(storage, fileDAO, usageDAO, orphanDAO, referenceDAO, &args.GCConfig, logger)
+		runs = append(runs, func(ctx context.Context) error {
+			if err := collector.Run(ctx); err != nil {
+				logger.Error("garbage collector stopped", zap.Error(err))
+				return err
+			}
+			return nil
+		})
+	}
 
-	<-shutdownCh
+	if err := runkit.GracefulRun(runkit.Group(runs...), &args.GracefulConfig); err != nil {
+		logger.Error("file server stopped", zap.Error(err))
+		return err
+	}
 
 	return nil
 }
+
+func serveHTTP(addr string, handler http.Handler, logger *logkit.Logger) runkit.GracefulRunFunc {
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	return func(ctx context.Context) error {
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal("failed to run HTTP server", zap.Error(err))
+			}
+		}()
+
+		<-ctx.Done()
+
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			logger.Error("failed to shutdown HTTP server", zap.Error(err))
+		}
+
+		return nil
+	}
+}
+
+// newReferenceDAO reads the collections of the api service that point at files.
+func newReferenceDAO(mongoClient *mongokit.MongoClient) dao.ReferenceDAO {
+	database := mongoClient.Database()
+	return dao.NewMongoReferenceDAO(database.Collection("posts"), database.Collection("revisions"), database.Collection("users"))
+}
@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Comment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	PostID    primitive.ObjectID `bson:"post_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	ParentID  primitive.ObjectID `bson:"parent_id,omitempty"`
+	Content   string             `bson:"content,omitempty"`
+	Deleted   bool               `bson:"deleted,omitempty"`
+	CreatedAT time.Time          `bson:"created_at,omitempty"`
+	UpdatedAT time.Time          `bson:"updated_at,omitempty"`
+}
+
+func (c *Comment) ToProto(userName string) *pb.CommentInfo {
+	var parentID string
+	if !c.ParentID.IsZero() {
+		parentID = c.ParentID.Hex()
+	}
+
+	return &pb.CommentInfo{
+		CommentId: c.ID.Hex(),
+		PostId:    c.PostID.Hex(),
+		UserId:    c.UserID.Hex(),
+		UserName:  userName,
+		ParentId:  parentID,
+		Content:   c.Content,
+		Deleted:   c.Deleted,
+		CreatedAt: timestamppb.New(c.CreatedAT),
+		UpdatedAt: timestamppb.New(c.UpdatedAT),
+	}
+}
+
+type CommentDAO interface {
+	Get(ctx context.Context, id primitive.ObjectID) (*Comment, error)
+	ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*Comment, error)
+	Create(ctx context.Context, comment *Comment) (primitive.ObjectID, error)
+	UpdateContent(ctx context.Context, comment *Comment) error
+	// Delete tombstones the comment so that its replies stay attached to the thread.
+	Delete(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) error
+	DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+)
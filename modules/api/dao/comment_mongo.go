@@ -0,0 +1,131 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoCommentDAO struct {
+	collection *mongo.Collection
+}
+
+var _ CommentDAO = (*mongoCommentDAO)(nil)
+
+func NewMongoCommentDAO(collection *mongo.Collection) *mongoCommentDAO {
+	return &mongoCommentDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoCommentDAO) CreateIndex(ctx context.Context) error {
+	model := mongo.IndexModel{Keys: bson.D{{"post_id", 1}, {"created_at", 1}}}
+	_, err := dao.collection.Indexes().CreateOne(ctx, model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoCommentDAO) Get(ctx context.Context, id primitive.ObjectID) (*Comment, error) {
+	var comment Comment
+	if err := dao.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCommentNotFound
+		}
+
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (dao *mongoCommentDAO) ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*Comment, error) {
+	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.D{{"created_at", 1}})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{"post_id": postID}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]*Comment, 0)
+	for cursor.Next(ctx) {
+		var comment Comment
+		if err := cursor.Decode(&comment); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, &comment)
+	}
+
+	return comments, nil
+}
+
+func (dao *mongoCommentDAO) Create(ctx context.Context, comment *Comment) (primitive.ObjectID, error) {
+	result, err := dao.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+
+	return comment.ID, nil
+}
+
+func (dao *mongoCommentDAO) UpdateContent(ctx context.Context, comment *Comment) error {
+	if result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":     comment.ID,
+			"user_id": comment.UserID,
+			"deleted": bson.M{"$ne": true},
+		},
+		bson.M{
+			"$set": bson.M{
+				"content":    comment.Content,
+				"updated_at": time.Now(),
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoCommentDAO) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	if result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":     id,
+			"user_id": userID,
+			"deleted": bson.M{"$ne": true},
+		},
+		bson.M{
+			"$set": bson.M{
+				"content":    "",
+				"deleted":    true,
+				"updated_at": time.Now(),
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoCommentDAO) DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
@@ -0,0 +1,158 @@
+package dao
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PageCursor points right after the last document of a page. Listings sorted by time use UpdatedAT,
+// searches use Score, and ID breaks the ties.
+type PageCursor struct {
+	UpdatedAT time.Time
+	Score     float64
+	ID        primitive.ObjectID
+	// kind is the order of the listing the cursor was issued by, it can only resume a listing in that order
+	kind cursorKind
+}
+
+type cursorKind string
+
+const (
+	cursorByUpdatedAT cursorKind = "u"
+	cursorByScore     cursorKind = "s"
+	cursorByID        cursorKind = "id"
+)
+
+func newUpdatedATCursor(updatedAT time.Time, id primitive.ObjectID) *PageCursor {
+	return &PageCursor{UpdatedAT: updatedAT, ID: id, kind: cursorByUpdatedAT}
+}
+
+func newScoreCursor(score float64, id primitive.ObjectID) *PageCursor {
+	return &PageCursor{Score: score, ID: id, kind: cursorByScore}
+}
+
+func newIDCursor(id primitive.ObjectID) *PageCursor {
+	return &PageCursor{ID: id, kind: cursorByID}
+}
+
+type pageToken struct {
+	Kind      cursorKind `json:"k"`
+	UpdatedAT int64      `json:"u,omitempty"`
+	Score     float64    `json:"s,omitempty"`
+	ID        string     `json:"id"`
+}
+
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// Encode returns the opaque page token of the cursor.
+func (c *PageCursor) Encode() string {
+	if c == nil {
+		return ""
+	}
+
+	token := pageToken{
+		Kind:  c.kind,
+		Score: c.Score,
+		ID:    c.ID.Hex(),
+	}
+	if !c.UpdatedAT.IsZero() {
+		token.UpdatedAT = c.UpdatedAT.UnixMilli()
+	}
+
+	b, _ := json.Marshal(token)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageCursor parses a page token, an empty token means the first page and returns nil.
+func DecodePageCursor(s string) (*PageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	var token pageToken
+	if err := json.Unmarshal(b, &token); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	id, err := primitive.ObjectIDFromHex(token.ID)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	switch token.Kind {
+	case cursorByUpdatedAT:
+		if token.UpdatedAT == 0 {
+			return nil, ErrInvalidPageToken
+		}
+		return newUpdatedATCursor(time.UnixMilli(token.UpdatedAT), id), nil
+	case cursorByScore:
+		return newScoreCursor(token.Score, id), nil
+	case cursorByID:
+		return newIDCursor(id), nil
+	default:
+		return nil, ErrInvalidPageToken
+	}
+}
+
+// afterUpdatedAT matches the documents after the cursor in (updated_at desc, _id desc) order, a cursor of
+// another order is rejected rather than read as the zero time.
+func (c *PageCursor) afterUpdatedAT() (bson.M, error) {
+	if c.kind != cursorByUpdatedAT {
+		return nil, ErrInvalidPageToken
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"updated_at": bson.M{"$lt": c.UpdatedAT}},
+		bson.M{"updated_at": c.UpdatedAT, "_id": bson.M{"$lt": c.ID}},
+	}}, nil
+}
+
+// afterScore matches the documents after the cursor in (score desc, _id desc) order.
+func (c *PageCursor) afterScore() (bson.M, error) {
+	if c.kind != cursorByScore {
+		return nil, ErrInvalidPageToken
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"score": bson.M{"$lt": c.Score}},
+		bson.M{"score": c.Score, "_id": bson.M{"$lt": c.ID}},
+	}}, nil
+}
+
+// afterID matches the documents after the cursor in _id asc order.
+func (c *PageCursor) afterID() (bson.M, error) {
+	if c.kind != cursorByID {
+		return nil, ErrInvalidPageToken
+	}
+
+	return bson.M{"_id": bson.M{"$gt": c.ID}}, nil
+}
+
+// andFilter combines the conditions into one filter.
+func andFilter(conds ...bson.M) bson.M {
+	switch len(conds) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conds[0]
+	default:
+		a := make(bson.A, 0, len(conds))
+		for _, cond := range conds {
+			a = append(a, cond)
+		}
+		return bson.M{"$and": a}
+	}
+}
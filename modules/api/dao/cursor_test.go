@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	id := primitive.NewObjectID()
+	updatedAT := time.UnixMilli(time.Now().UnixMilli())
+
+	tests := []struct {
+		name   string
+		cursor *PageCursor
+	}{
+		{name: "updated_at", cursor: newUpdatedATCursor(updatedAT, id)},
+		{name: "score", cursor: newScoreCursor(1.25, id)},
+		{name: "id", cursor: newIDCursor(id)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cursor, err := DecodePageCursor(test.cursor.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cursor.kind != test.cursor.kind || !cursor.UpdatedAT.Equal(test.cursor.UpdatedAT) ||
+				cursor.Score != test.cursor.Score || cursor.ID != test.cursor.ID {
+				t.Errorf("decoded %+v, want %+v", cursor, test.cursor)
+			}
+		})
+	}
+
+	if cursor, err := DecodePageCursor(""); cursor != nil || err != nil {
+		t.Errorf("decoded the empty token as %v, %v, want the first page", cursor, err)
+	}
+	if token := (*PageCursor)(nil).Encode(); token != "" {
+		t.Errorf("encoded the last page as %q, want no token", token)
+	}
+}
+
+func TestDecodePageCursorInvalid(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a token!"},
+		{name: "not json", token: encode("not json")},
+		{name: "invalid id", token: encode(`{"k":"id","id":"nope"}`)},
+		{name: "no kind", token: encode(`{"u":1650000000000,"id":"` + id + `"}`)},
+		{name: "unknown kind", token: encode(`{"k":"x","id":"` + id + `"}`)},
+		{name: "updated_at kind without time", token: encode(`{"k":"u","id":"` + id + `"}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := DecodePageCursor(test.token); !errors.Is(err, ErrInvalidPageToken) {
+				t.Errorf("DecodePageCursor returned %v, want %v", err, ErrInvalidPageToken)
+			}
+		})
+	}
+}
+
+func TestPageCursorKindMismatch(t *testing.T) {
+	id := primitive.NewObjectID()
+	cursors := map[cursorKind]*PageCursor{
+		cursorByUpdatedAT: newUpdatedATCursor(time.Now(), id),
+		cursorByScore:     newScoreCursor(1, id),
+		cursorByID:        newIDCursor(id),
+	}
+	after := map[cursorKind]func(*PageCursor) error{
+		cursorByUpdatedAT: func(c *PageCursor) error { _, err := c.afterUpdatedAT(); return err },
+		cursorByScore:     func(c *PageCursor) error { _, err := c.afterScore(); return err },
+		cursorByID:        func(c *PageCursor) error { _, err := c.afterID(); return err },
+	}
+
+	for issued, cursor := range cursors {
+		for listing, fn := range after {
+			err := fn(cursor)
+			if issued == listing && err != nil {
+				t.Errorf("a %q cursor was rejected by its own listing: %v", issued, err)
+			}
+			if issued != listing && !errors.Is(err, ErrInvalidPageToken) {
+				t.Errorf("a %q cursor resumed a %q listing, want %v", issued, listing, ErrInvalidPageToken)
+			}
+		}
+	}
+}
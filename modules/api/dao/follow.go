@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Follow struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	FollowerID primitive.ObjectID `bson:"follower_id,omitempty"`
+	FolloweeID primitive.ObjectID `bson:"followee_id,omitempty"`
+	CreatedAT  time.Time          `bson:"created_at,omitempty"`
+}
+
+type FollowDAO interface {
+	// Create records that follower follows followee, it returns false if it already does.
+	Create(ctx context.Context, followerID, followeeID primitive.ObjectID) (bool, error)
+	// Delete removes the follow, it returns false if there was nothing to remove.
+	Delete(ctx context.Context, followerID, followeeID primitive.ObjectID) (bool, error)
+	// ListFollowers returns the follows of the users following userID, newest first.
+	ListFollowers(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Follow, error)
+	// ListFollowing returns the follows of the users followed by userID, newest first.
+	ListFollowing(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Follow, error)
+	ListFollowerIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	ListFolloweeIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	// DeleteByUserID removes every follow from and to the user.
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
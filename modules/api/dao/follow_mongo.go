@@ -0,0 +1,143 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFollowDAO struct {
+	collection *mongo.Collection
+}
+
+var _ FollowDAO = (*mongoFollowDAO)(nil)
+
+func NewMongoFollowDAO(collection *mongo.Collection) *mongoFollowDAO {
+	return &mongoFollowDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoFollowDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"follower_id", 1}, {"followee_id", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"followee_id", 1}, {"created_at", -1}},
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoFollowDAO) Create(ctx context.Context, followerID, followeeID primitive.ObjectID) (bool, error) {
+	result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"follower_id": followerID,
+			"followee_id": followeeID,
+		},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"created_at": time.Now(),
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		// a concurrent follow of the same user has won the race on the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return result.UpsertedCount == 1, nil
+}
+
+func (dao *mongoFollowDAO) Delete(ctx context.Context, followerID, followeeID primitive.ObjectID) (bool, error) {
+	result, err := dao.collection.DeleteOne(ctx, bson.M{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+func (dao *mongoFollowDAO) ListFollowers(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Follow, error) {
+	return dao.list(ctx, bson.M{"followee_id": userID}, limit, skip)
+}
+
+func (dao *mongoFollowDAO) ListFollowing(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Follow, error) {
+	return dao.list(ctx, bson.M{"follower_id": userID}, limit, skip)
+}
+
+func (dao *mongoFollowDAO) list(ctx context.Context, filter bson.M, limit, skip int64) ([]*Follow, error) {
+	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.D{{"created_at", -1}})
+
+	cursor, err := dao.collection.Find(ctx, filter, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	follows := make([]*Follow, 0)
+	for cursor.Next(ctx) {
+		var follow Follow
+		if err := cursor.Decode(&follow); err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, &follow)
+	}
+
+	return follows, nil
+}
+
+func (dao *mongoFollowDAO) ListFollowerIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	follows, err := dao.list(ctx, bson.M{"followee_id": userID}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FollowerID)
+	}
+
+	return ids, nil
+}
+
+func (dao *mongoFollowDAO) ListFolloweeIDs(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	follows, err := dao.list(ctx, bson.M{"follower_id": userID}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FolloweeID)
+	}
+
+	return ids, nil
+}
+
+func (dao *mongoFollowDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"follower_id": userID},
+		bson.M{"followee_id": userID},
+	}})
+	return err
+}
@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Identity links the subject of an OpenID Connect provider to a user.
+type Identity struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Issuer    string             `bson:"issuer,omitempty"`
+	Subject   string             `bson:"subject,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	CreatedAT time.Time          `bson:"created_at,omitempty"`
+}
+
+type IdentityDAO interface {
+	Get(ctx context.Context, issuer, subject string) (*Identity, error)
+	// Create returns ErrIdentityExists if the subject is already linked to a user.
+	Create(ctx context.Context, identity *Identity) (primitive.ObjectID, error)
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityExists   = errors.New("identity already exists")
+)
@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoIdentityDAO struct {
+	collection *mongo.Collection
+}
+
+var _ IdentityDAO = (*mongoIdentityDAO)(nil)
+
+func NewMongoIdentityDAO(collection *mongo.Collection) *mongoIdentityDAO {
+	return &mongoIdentityDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoIdentityDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"issuer", 1}, {"subject", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"user_id", 1}},
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoIdentityDAO) Get(ctx context.Context, issuer, subject string) (*Identity, error) {
+	var identity Identity
+	if err := dao.collection.FindOne(ctx, bson.M{
+		"issuer":  issuer,
+		"subject": subject,
+	}).Decode(&identity); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrIdentityNotFound
+		}
+
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (dao *mongoIdentityDAO) Create(ctx context.Context, identity *Identity) (primitive.ObjectID, error) {
+	result, err := dao.collection.InsertOne(ctx, identity)
+	if err != nil {
+		// a concurrent first login of the same subject has won the race on the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return primitive.NilObjectID, ErrIdentityExists
+		}
+		return primitive.NilObjectID, err
+	}
+
+	identity.ID = result.InsertedID.(primitive.ObjectID)
+
+	return identity.ID, nil
+}
+
+func (dao *mongoIdentityDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
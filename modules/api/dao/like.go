@@ -0,0 +1,27 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Like struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	PostID    primitive.ObjectID `bson:"post_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	CreatedAT time.Time          `bson:"created_at,omitempty"`
+}
+
+type LikeDAO interface {
+	// Create records that the user likes the post, it returns false if the like already exists.
+	Create(ctx context.Context, postID, userID primitive.ObjectID) (bool, error)
+	// Delete removes the like of the user, it returns false if there was nothing to remove.
+	Delete(ctx context.Context, postID, userID primitive.ObjectID) (bool, error)
+	ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*Like, error)
+	// ListLikedPostIDs returns the subset of postIDs liked by the user.
+	ListLikedPostIDs(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) ([]primitive.ObjectID, error)
+	DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoLikeDAO struct {
+	collection *mongo.Collection
+}
+
+var _ LikeDAO = (*mongoLikeDAO)(nil)
+
+func NewMongoLikeDAO(collection *mongo.Collection) *mongoLikeDAO {
+	return &mongoLikeDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoLikeDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"post_id", 1}, {"user_id", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"user_id", 1}},
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoLikeDAO) Create(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"post_id": postID,
+			"user_id": userID,
+		},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"created_at": time.Now(),
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		// a concurrent like of the same user has won the race on the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return result.UpsertedCount == 1, nil
+}
+
+func (dao *mongoLikeDAO) Delete(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	result, err := dao.collection.DeleteOne(ctx, bson.M{
+		"post_id": postID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+func (dao *mongoLikeDAO) ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*Like, error) {
+	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.D{{"created_at", -1}})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{"post_id": postID}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	likes := make([]*Like, 0)
+	for cursor.Next(ctx) {
+		var like Like
+		if err := cursor.Decode(&like); err != nil {
+			return nil, err
+		}
+
+		likes = append(likes, &like)
+	}
+
+	return likes, nil
+}
+
+func (dao *mongoLikeDAO) ListLikedPostIDs(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(postIDs) == 0 {
+		return []primitive.ObjectID{}, nil
+	}
+
+	o := options.Find().SetProjection(bson.M{"post_id": 1})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{
+		"user_id": userID,
+		"post_id": bson.M{"$in": postIDs},
+	}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	liked := make([]primitive.ObjectID, 0)
+	for cursor.Next(ctx) {
+		var like Like
+		if err := cursor.Decode(&like); err != nil {
+			return nil, err
+		}
+
+		liked = append(liked, like.PostID)
+	}
+
+	return liked, nil
+}
+
+func (dao *mongoLikeDAO) DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
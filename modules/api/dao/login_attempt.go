@@ -0,0 +1,18 @@
+package dao
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttemptDAO counts failed logins per key, e.g. an account or a client address, and locks keys out.
+type LoginAttemptDAO interface {
+	// LockedFor returns how long the longest lock among keys still lasts, zero if none is locked.
+	LockedFor(ctx context.Context, keys ...string) (time.Duration, error)
+	// RecordFailure counts a failed login of key and returns the failures so far,
+	// the count is forgotten once key has had no failure for window.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// Reset forgets the failures and the lock of key.
+	Reset(ctx context.Context, key string) error
+}
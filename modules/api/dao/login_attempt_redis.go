@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	loginFailuresKeyPrefix = "login:failures:"
+	loginLockKeyPrefix     = "login:lock:"
+)
+
+type redisLoginAttemptDAO struct {
+	client *redis.Client
+}
+
+var _ LoginAttemptDAO = (*redisLoginAttemptDAO)(nil)
+
+func NewRedisLoginAttemptDAO(client *redis.Client) *redisLoginAttemptDAO {
+	return &redisLoginAttemptDAO{
+		client: client,
+	}
+}
+
+func (dao *redisLoginAttemptDAO) LockedFor(ctx context.Context, keys ...string) (time.Duration, error) {
+	ttls := make([]*redis.DurationCmd, 0, len(keys))
+	if _, err := dao.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			ttls = append(ttls, pipe.PTTL(ctx, loginLockKeyPrefix+key))
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	var longest time.Duration
+	for _, ttl := range ttls {
+		// missing keys have a negative TTL
+		if ttl.Val() > longest {
+			longest = ttl.Val()
+		}
+	}
+
+	return longest, nil
+}
+
+func (dao *redisLoginAttemptDAO) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var failures *redis.IntCmd
+	if _, err := dao.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Incr(ctx, loginFailuresKeyPrefix+key)
+		pipe.PExpire(ctx, loginFailuresKeyPrefix+key, window)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return failures.Val(), nil
+}
+
+func (dao *redisLoginAttemptDAO) Lock(ctx context.Context, key string, d time.Duration) error {
+	return dao.client.Set(ctx, loginLockKeyPrefix+key, 1, d).Err()
+}
+
+func (dao *redisLoginAttemptDAO) Reset(ctx context.Context, key string) error {
+	return dao.client.Del(ctx, loginFailuresKeyPrefix+key, loginLockKeyPrefix+key).Err()
+}
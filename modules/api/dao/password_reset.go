@@ -0,0 +1,30 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordReset is a pending password reset, only the hash of its token is stored.
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	TokenHash string             `bson:"token_hash,omitempty"`
+	ExpiresAT time.Time          `bson:"expires_at,omitempty"`
+	CreatedAT time.Time          `bson:"created_at,omitempty"`
+}
+
+type PasswordResetDAO interface {
+	Create(ctx context.Context, reset *PasswordReset) (primitive.ObjectID, error)
+	// Consume removes and returns the unexpired reset holding tokenHash, so that a token can only be used once.
+	Consume(ctx context.Context, tokenHash string) (*PasswordReset, error)
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrPasswordResetNotFound = errors.New("password reset not found")
+)
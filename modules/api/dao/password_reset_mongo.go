@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPasswordResetDAO struct {
+	collection *mongo.Collection
+}
+
+var _ PasswordResetDAO = (*mongoPasswordResetDAO)(nil)
+
+func NewMongoPasswordResetDAO(collection *mongo.Collection) *mongoPasswordResetDAO {
+	return &mongoPasswordResetDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoPasswordResetDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"token_hash", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"user_id", 1}},
+		},
+		{
+			// expired resets are removed by MongoDB
+			Keys:    bson.D{{"expires_at", 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoPasswordResetDAO) Create(ctx context.Context, reset *PasswordReset) (primitive.ObjectID, error) {
+	result, err := dao.collection.InsertOne(ctx, reset)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	reset.ID = result.InsertedID.(primitive.ObjectID)
+
+	return reset.ID, nil
+}
+
+func (dao *mongoPasswordResetDAO) Consume(ctx context.Context, tokenHash string) (*PasswordReset, error) {
+	var reset PasswordReset
+	if err := dao.collection.FindOneAndDelete(ctx, bson.M{
+		"token_hash": tokenHash,
+		// the TTL monitor only runs once a minute
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&reset); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPasswordResetNotFound
+		}
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+func (dao *mongoPasswordResetDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
@@ -10,6 +10,42 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
+
+var postStatusToProto = map[PostStatus]pb.PostStatus{
+	PostStatusDraft:     pb.PostStatus_POST_STATUS_DRAFT,
+	PostStatusScheduled: pb.PostStatus_POST_STATUS_SCHEDULED,
+	PostStatusPublished: pb.PostStatus_POST_STATUS_PUBLISHED,
+	PostStatusArchived:  pb.PostStatus_POST_STATUS_ARCHIVED,
+}
+
+func (s PostStatus) ToProto() pb.PostStatus {
+	// posts written before statuses existed are published
+	if s == "" {
+		return pb.PostStatus_POST_STATUS_PUBLISHED
+	}
+
+	return postStatusToProto[s]
+}
+
+// PostStatusFromProto converts the status of a request, it returns an empty status if unspecified.
+func PostStatusFromProto(status pb.PostStatus) PostStatus {
+	for s, p := range postStatusToProto {
+		if p == status {
+			return s
+		}
+	}
+
+	return ""
+}
+
 type Post struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
 	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
@@ -19,11 +55,27 @@ type Post struct {
 	Likes     int                `bson:"likes,omitempty"`
 	Tags      []string           `bson:"tags,omitempty"`
 	Image     string             `bson:"image,omitempty"`
+	Status    PostStatus         `bson:"status,omitempty"`
+	PublishAT time.Time          `bson:"publish_at,omitempty"`
 	CreatedAT time.Time          `bson:"created_at,omitempty"`
 	UpdatedAT time.Time          `bson:"updated_at,omitempty"`
 }
 
+func (p *Post) IsPublished() bool {
+	return p.Status == "" || p.Status == PostStatusPublished
+}
+
+// IsVisibleTo reports whether the user can read the post, only authors can read their unpublished posts.
+func (p *Post) IsVisibleTo(userID primitive.ObjectID) bool {
+	return p.IsPublished() || (!userID.IsZero() && p.UserID == userID)
+}
+
 func (p *Post) ToProto(userName string) *pb.PostInfo {
+	var publishAt *timestamppb.Timestamp
+	if !p.PublishAT.IsZero() {
+		publishAt = timestamppb.New(p.PublishAT)
+	}
+
 	return &pb.PostInfo{
 		PostId:    p.ID.Hex(),
 		UserId:    p.UserID.Hex(),
@@ -34,6 +86,8 @@ func (p *Post) ToProto(userName string) *pb.PostInfo {
 		Likes:     uint32(p.Likes),
 		Tags:      p.Tags,
 		Image:     p.Image,
+		Status:    p.Status.ToProto(),
+		PublishAt: publishAt,
 		CreatedAt: timestamppb.New(p.CreatedAT),
 		UpdatedAt: timestamppb.New(p.UpdatedAT),
 	}
@@ -41,12 +95,35 @@ func (p *Post) ToProto(userName string) *pb.PostInfo {
 
 type PostDAO interface {
 	Get(ctx context.Context, id primitive.ObjectID) (*Post, error)
-	List(ctx context.Context, limit, skip int64, filter string) ([]*Post, error)
-	ListByUserID(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Post, error)
+	// List returns published posts, plus the unpublished posts of viewerID if it is not nil.
+	// filter is a full-text search while tag only keeps the posts having exactly that tag.
+	// When cursor is not nil, skip is ignored and the page starts right after the cursor.
+	// The returned cursor points to the next page and is nil on the last page.
+	List(
+		ctx context.Context,
+		limit, skip int64,
+		filter, tag string,
+		viewerID primitive.ObjectID,
+		cursor *PageCursor,
+	) ([]*Post, *PageCursor, error)
+	// ListByUserID returns the published posts of the user, or all of them if the viewer is the user.
+	ListByUserID(
+		ctx context.Context,
+		userID primitive.ObjectID,
+		limit, skip int64,
+		viewerID primitive.ObjectID,
+		cursor *PageCursor,
+	) ([]*Post, *PageCursor, error)
+	// ListByUserIDs returns the published posts of the users, newest first. See List for the cursor.
+	ListByUserIDs(ctx context.Context, userIDs []primitive.ObjectID, limit int64, cursor *PageCursor) ([]*Post, *PageCursor, error)
 	Create(ctx context.Context, post *Post) (primitive.ObjectID, error)
 	UpdateContent(ctx context.Context, post *Post) error
-	UpdateLikes(ctx context.Context, id primitive.ObjectID) error
-	UpdateViews(ctx context.Context, id primitive.ObjectID) error
+	// UpdateLikes adjusts the denormalized likes counter by delta and returns the new count.
+	UpdateLikes(ctx context.Context, id primitive.ObjectID, delta int) (int, error)
+	// UpdateViews adds the aggregated views to the post.
+	UpdateViews(ctx context.Context, id primitive.ObjectID, views int) error
+	// PublishScheduled publishes the scheduled posts whose publish time has come.
+	PublishScheduled(ctx context.Context, now time.Time) (int64, error)
 	Delete(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) error
 	CreateIndex(ctx context.Context) error
 }
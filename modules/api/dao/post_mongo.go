@@ -24,25 +24,29 @@ func NewMongoPostDAO(collection *mongo.Collection) *mongoPostDAO {
 }
 
 func (dao *mongoPostDAO) CreateIndex(ctx context.Context) error {
-	model := mongo.IndexModel{Keys: bson.D{{"title", "text"}, {"tags", "text"}}}
-	_, err := dao.collection.Indexes().CreateOne(ctx, model)
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{"title", "text"}, {"tags", "text"}}},
+		{Keys: bson.D{{"status", 1}, {"publish_at", 1}}},
+		{Keys: bson.D{{"updated_at", -1}, {"_id", -1}}},
+		{Keys: bson.D{{"user_id", 1}, {"updated_at", -1}, {"_id", -1}}},
+		{Keys: bson.D{{"tags", 1}, {"updated_at", -1}, {"_id", -1}}},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// publishedFilter matches published posts, posts without status were created before statuses existed.
+func publishedFilter() bson.M {
+	return bson.M{"status": bson.M{"$in": bson.A{PostStatusPublished, nil}}}
+}
+
 func (dao *mongoPostDAO) Get(ctx context.Context, id primitive.ObjectID) (*Post, error) {
 	var post Post
-	filter := bson.M{"_id": id}
-	update := bson.M{"$inc": bson.M{"views": 1}}
-
-	if err := dao.collection.FindOneAndUpdate(
-		ctx,
-		filter,
-		update,
-	).Decode(&post); err != nil {
-		if errors.Is(err, mongo.ErrNilDocument) {
+	if err := dao.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, ErrPostNotFound
 		}
 		return nil, err
@@ -51,43 +55,148 @@ func (dao *mongoPostDAO) Get(ctx context.Context, id primitive.ObjectID) (*Post,
 	return &post, nil
 }
 
-func (dao *mongoPostDAO) List(ctx context.Context, limit, skip int64, filter string) ([]*Post, error) {
-	var f bson.M
-	var sort bson.D
+func (dao *mongoPostDAO) List(
+	ctx context.Context,
+	limit, skip int64,
+	filter, tag string,
+	viewerID primitive.ObjectID,
+	cursor *PageCursor,
+) ([]*Post, *PageCursor, error) {
+	conds := []bson.M{publishedFilter()}
+	if !viewerID.IsZero() {
+		conds[0] = bson.M{"$or": bson.A{publishedFilter(), bson.M{"user_id": viewerID}}}
+	}
+	if tag != "" {
+		conds = append(conds, bson.M{"tags": tag})
+	}
 
 	if filter != "" {
-		f = bson.D{{"$text", bson.D{{"$search", filter}}}}.Map()
-		sort = bson.D{{"score", bson.D{{"$meta", "textScore"}}}, {"updated_at", -1}}
-	} else {
-		sort = bson.D{{"updated_at", -1}}
+		return dao.search(ctx, limit, skip, filter, andFilter(conds...), cursor)
 	}
-	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(sort)
 
-	cursor, err := dao.collection.Find(ctx, f, o)
+	if cursor != nil {
+		after, err := cursor.afterUpdatedAT()
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, after)
+		skip = 0
+	}
+
+	return dao.find(ctx, andFilter(conds...), limit, skip)
+}
+
+// search ranks the posts matching both the text filter and match by their text score.
+func (dao *mongoPostDAO) search(
+	ctx context.Context,
+	limit, skip int64,
+	filter string,
+	match bson.M,
+	cursor *PageCursor,
+) ([]*Post, *PageCursor, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", andFilter(bson.M{"$text": bson.M{"$search": filter}}, match)}},
+		{{"$addFields", bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if cursor != nil {
+		after, err := cursor.afterScore()
+		if err != nil {
+			return nil, nil, err
+		}
+		pipeline = append(pipeline, bson.D{{"$match", after}})
+		skip = 0
+	}
+	pipeline = append(pipeline,
+		bson.D{{"$sort", bson.D{{"score", -1}, {"_id", -1}}}},
+		bson.D{{"$skip", skip}},
+		// fetch one more post to know whether there is a next page
+		bson.D{{"$limit", limit + 1}},
+	)
+
+	c, err := dao.collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer cursor.Close(ctx)
+	defer c.Close(ctx)
 
+	var scores []float64
 	posts := make([]*Post, 0)
-	for cursor.Next(ctx) {
-		var post Post
+	for c.Next(ctx) {
+		var post struct {
+			Post  `bson:",inline"`
+			Score float64 `bson:"score"`
+		}
+		if err := c.Decode(&post); err != nil {
+			return nil, nil, err
+		}
 
-		if err := cursor.Decode(&post); err != nil {
-			return nil, err
+		posts = append(posts, &post.Post)
+		scores = append(scores, post.Score)
+	}
+
+	if int64(len(posts)) <= limit {
+		return posts, nil, nil
+	}
+
+	posts = posts[:limit]
+	last := posts[len(posts)-1]
+
+	return posts, newScoreCursor(scores[len(posts)-1], last.ID), nil
+}
+
+func (dao *mongoPostDAO) ListByUserID(
+	ctx context.Context,
+	userID primitive.ObjectID,
+	limit, skip int64,
+	viewerID primitive.ObjectID,
+	cursor *PageCursor,
+) ([]*Post, *PageCursor, error) {
+	conds := []bson.M{{"user_id": userID}}
+	if viewerID != userID {
+		conds = append(conds, publishedFilter())
+	}
+	if cursor != nil {
+		after, err := cursor.afterUpdatedAT()
+		if err != nil {
+			return nil, nil, err
 		}
-		posts = append(posts, &post)
+		conds = append(conds, after)
+		skip = 0
+	}
+
+	return dao.find(ctx, andFilter(conds...), limit, skip)
+}
+
+func (dao *mongoPostDAO) ListByUserIDs(
+	ctx context.Context,
+	userIDs []primitive.ObjectID,
+	limit int64,
+	cursor *PageCursor,
+) ([]*Post, *PageCursor, error) {
+	if len(userIDs) == 0 {
+		return []*Post{}, nil, nil
 	}
 
-	return posts, nil
+	conds := []bson.M{{"user_id": bson.M{"$in": userIDs}}, publishedFilter()}
+	if cursor != nil {
+		after, err := cursor.afterUpdatedAT()
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, after)
+	}
+
+	return dao.find(ctx, andFilter(conds...), limit, 0)
 }
 
-func (dao *mongoPostDAO) ListByUserID(ctx context.Context, userID primitive.ObjectID, limit, skip int64) ([]*Post, error) {
-	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.D{{"updated_at", -1}})
+// find lists the posts in (updated_at desc, _id desc) order and returns the cursor of the next page.
+func (dao *mongoPostDAO) find(ctx context.Context, filter bson.M, limit, skip int64) ([]*Post, *PageCursor, error) {
+	// fetch one more post to know whether there is a next page
+	o := options.Find().SetLimit(limit + 1).SetSkip(skip).SetSort(bson.D{{"updated_at", -1}, {"_id", -1}})
 
-	cursor, err := dao.collection.Find(ctx, bson.M{"user_id": userID}, o)
+	cursor, err := dao.collection.Find(ctx, filter, o)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer cursor.Close(ctx)
 
@@ -95,13 +204,20 @@ func (dao *mongoPostDAO) ListByUserID(ctx context.Context, userID primitive.Obje
 	for cursor.Next(ctx) {
 		var post Post
 		if err := cursor.Decode(&post); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		posts = append(posts, &post)
 	}
 
-	return posts, nil
+	if int64(len(posts)) <= limit {
+		return posts, nil, nil
+	}
+
+	posts = posts[:limit]
+	last := posts[len(posts)-1]
+
+	return posts, newUpdatedATCursor(last.UpdatedAT, last.ID), nil
 }
 
 func (dao *mongoPostDAO) Create(ctx context.Context, post *Post) (primitive.ObjectID, error) {
@@ -116,6 +232,19 @@ func (dao *mongoPostDAO) Create(ctx context.Context, post *Post) (primitive.Obje
 }
 
 func (dao *mongoPostDAO) UpdateContent(ctx context.Context, post *Post) error {
+	set := bson.M{
+		"title":      post.Title,
+		"content":    post.Content,
+		"image":      post.Image,
+		"tags":       post.Tags,
+		"updated_at": time.Now(),
+	}
+	// the status is only changed when requested
+	if post.Status != "" {
+		set["status"] = post.Status
+		set["publish_at"] = post.PublishAT
+	}
+
 	if result, err := dao.collection.UpdateOne(
 		ctx,
 		bson.M{
@@ -123,13 +252,7 @@ func (dao *mongoPostDAO) UpdateContent(ctx context.Context, post *Post) error {
 			"user_id": post.UserID,
 		},
 		bson.M{
-			"$set": bson.M{
-				"title":      post.Title,
-				"content":    post.Content,
-				"image":      post.Image,
-				"tags":       post.Tags,
-				"updated_at": time.Now(),
-			},
+			"$set": set,
 		},
 	); err != nil {
 		return err
@@ -140,42 +263,70 @@ func (dao *mongoPostDAO) UpdateContent(ctx context.Context, post *Post) error {
 	return nil
 }
 
-func (dao *mongoPostDAO) UpdateLikes(ctx context.Context, id primitive.ObjectID) error {
-	if result, err := dao.collection.UpdateOne(
+func (dao *mongoPostDAO) UpdateLikes(ctx context.Context, id primitive.ObjectID, delta int) (int, error) {
+	var post Post
+	o := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"likes": 1})
+
+	if err := dao.collection.FindOneAndUpdate(
 		ctx,
 		bson.M{"_id": id},
 		bson.M{
 			"$inc": bson.M{
-				"likes": 1,
+				"likes": delta,
 			},
 		},
-	); err != nil {
-		return err
-	} else if result.ModifiedCount == 0 {
-		return ErrPostNotFound
+		o,
+	).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, ErrPostNotFound
+		}
+		return 0, err
 	}
 
-	return nil
+	return post.Likes, nil
 }
 
-func (dao *mongoPostDAO) UpdateViews(ctx context.Context, id primitive.ObjectID) error {
+func (dao *mongoPostDAO) UpdateViews(ctx context.Context, id primitive.ObjectID, views int) error {
 	if result, err := dao.collection.UpdateOne(
 		ctx,
 		bson.M{"_id": id},
 		bson.M{
 			"$inc": bson.M{
-				"views": 1,
+				"views": views,
 			},
 		},
 	); err != nil {
 		return err
-	} else if result.ModifiedCount == 0 {
+	} else if result.MatchedCount == 0 {
 		return ErrPostNotFound
 	}
 
 	return nil
 }
 
+func (dao *mongoPostDAO) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	result, err := dao.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"status":     PostStatusScheduled,
+			"publish_at": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":     PostStatusPublished,
+				"updated_at": now,
+			},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
 func (dao *mongoPostDAO) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
 	if result, err := dao.collection.DeleteOne(ctx, bson.M{
 		"_id":     id,
@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// postDocs returns n posts as the server sends them, in (updated_at desc, _id desc) order.
+func postDocs(n int) []bson.D {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	docs := make([]bson.D, 0, n)
+	for i := 0; i < n; i++ {
+		docs = append(docs, bson.D{
+			{"_id", primitive.NewObjectID()},
+			{"title", "post"},
+			{"updated_at", now.Add(-time.Duration(i) * time.Minute)},
+		})
+	}
+
+	return docs
+}
+
+func TestMongoPostDAOListPages(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	previous := newUpdatedATCursor(time.Now(), primitive.NewObjectID())
+
+	tests := []struct {
+		name   string
+		limit  int64
+		skip   int64
+		cursor *PageCursor
+		// found is the number of posts the server returns for the limit+1 requested
+		found int
+		// want is the number of posts returned, and whether there is a next page
+		want     int
+		wantNext bool
+		wantSkip int64
+	}{
+		{name: "first page", limit: 2, found: 3, want: 2, wantNext: true},
+		{name: "last page", limit: 2, found: 2, want: 2},
+		{name: "short page", limit: 2, found: 1, want: 1},
+		{name: "empty", limit: 2, want: 0},
+		{name: "skip without cursor", limit: 2, skip: 4, found: 3, want: 2, wantNext: true, wantSkip: 4},
+		{name: "cursor ignores skip", limit: 2, skip: 4, cursor: previous, found: 1, want: 1},
+	}
+
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			docs := postDocs(test.found)
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "blog.posts", mtest.FirstBatch, docs...))
+
+			posts, next, err := NewMongoPostDAO(mt.Coll).List(context.Background(), test.limit, test.skip, "", "", primitive.NilObjectID, test.cursor)
+			if err != nil {
+				mt.Fatal(err)
+			}
+
+			cmd := mt.GetStartedEvent().Command
+			if limit := cmd.Lookup("limit").Int64(); limit != test.limit+1 {
+				mt.Errorf("requested %d posts, want %d to know about the next page", limit, test.limit+1)
+			}
+			if skip := cmd.Lookup("skip").Int64(); skip != test.wantSkip {
+				mt.Errorf("skipped %d posts, want %d", skip, test.wantSkip)
+			}
+			filter := cmd.Lookup("filter").Document().String()
+			if resumed := strings.Contains(filter, `"updated_at": {"$lt"`); resumed != (test.cursor != nil) {
+				mt.Errorf("the filter %s resumes after a cursor: %v, want %v", filter, resumed, test.cursor != nil)
+			}
+
+			if len(posts) != test.want {
+				mt.Fatalf("returned %d posts, want %d", len(posts), test.want)
+			}
+			if (next != nil) != test.wantNext {
+				mt.Fatalf("returned next cursor %v, want one: %v", next, test.wantNext)
+			}
+			if next == nil {
+				return
+			}
+
+			// the next page starts after the last returned post, not the extra one
+			last := posts[len(posts)-1]
+			decoded, err := DecodePageCursor(next.Encode())
+			if err != nil {
+				mt.Fatal(err)
+			}
+			if decoded.ID != last.ID || !decoded.UpdatedAT.Equal(last.UpdatedAT) {
+				mt.Errorf("next page starts after %s at %v, want %s at %v", decoded.ID.Hex(), decoded.UpdatedAT, last.ID.Hex(), last.UpdatedAT)
+			}
+		})
+	}
+}
+
+func TestMongoPostDAORejectsCursorOfAnotherListing(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	id := primitive.NewObjectID()
+	search := newScoreCursor(1.5, id)
+	plain := newUpdatedATCursor(time.Now(), id)
+
+	tests := []struct {
+		name string
+		list func(dao *mongoPostDAO) error
+	}{
+		{
+			name: "search cursor on a listing",
+			list: func(dao *mongoPostDAO) error {
+				_, _, err := dao.List(context.Background(), 2, 0, "", "", primitive.NilObjectID, search)
+				return err
+			},
+		},
+		{
+			name: "listing cursor on a search",
+			list: func(dao *mongoPostDAO) error {
+				_, _, err := dao.List(context.Background(), 2, 0, "golang", "", primitive.NilObjectID, plain)
+				return err
+			},
+		},
+		{
+			name: "search cursor on the posts of a user",
+			list: func(dao *mongoPostDAO) error {
+				_, _, err := dao.ListByUserID(context.Background(), id, 2, 0, primitive.NilObjectID, search)
+				return err
+			},
+		},
+		{
+			name: "search cursor on the feed",
+			list: func(dao *mongoPostDAO) error {
+				_, _, err := dao.ListByUserIDs(context.Background(), []primitive.ObjectID{id}, 2, search)
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			if err := test.list(NewMongoPostDAO(mt.Coll)); !errors.Is(err, ErrInvalidPageToken) {
+				mt.Errorf("listing returned %v, want %v", err, ErrInvalidPageToken)
+			}
+			if event := mt.GetStartedEvent(); event != nil {
+				mt.Errorf("sent %s with a cursor of another listing", event.CommandName)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PostRevision is a snapshot of a post taken right before the post was changed.
+type PostRevision struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	PostID    primitive.ObjectID `bson:"post_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	Title     string             `bson:"title,omitempty"`
+	Content   string             `bson:"content,omitempty"`
+	Image     string             `bson:"image,omitempty"`
+	Tags      []string           `bson:"tags,omitempty"`
+	CreatedAT time.Time          `bson:"created_at,omitempty"`
+}
+
+func NewPostRevision(post *Post) *PostRevision {
+	return &PostRevision{
+		PostID:    post.ID,
+		UserID:    post.UserID,
+		Title:     post.Title,
+		Content:   post.Content,
+		Image:     post.Image,
+		Tags:      post.Tags,
+		CreatedAT: time.Now(),
+	}
+}
+
+func (r *PostRevision) ToProto() *pb.PostRevisionInfo {
+	return &pb.PostRevisionInfo{
+		RevisionId: r.ID.Hex(),
+		PostId:     r.PostID.Hex(),
+		Title:      r.Title,
+		Content:    r.Content,
+		Image:      r.Image,
+		Tags:       r.Tags,
+		CreatedAt:  timestamppb.New(r.CreatedAT),
+	}
+}
+
+type RevisionDAO interface {
+	Get(ctx context.Context, id primitive.ObjectID) (*PostRevision, error)
+	ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*PostRevision, error)
+	Create(ctx context.Context, revision *PostRevision) (primitive.ObjectID, error)
+	DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+)
@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRevisionDAO struct {
+	collection *mongo.Collection
+}
+
+var _ RevisionDAO = (*mongoRevisionDAO)(nil)
+
+func NewMongoRevisionDAO(collection *mongo.Collection) *mongoRevisionDAO {
+	return &mongoRevisionDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoRevisionDAO) CreateIndex(ctx context.Context) error {
+	model := mongo.IndexModel{Keys: bson.D{{"post_id", 1}, {"created_at", -1}}}
+	_, err := dao.collection.Indexes().CreateOne(ctx, model)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoRevisionDAO) Get(ctx context.Context, id primitive.ObjectID) (*PostRevision, error) {
+	var revision PostRevision
+	if err := dao.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&revision); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRevisionNotFound
+		}
+
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+func (dao *mongoRevisionDAO) ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*PostRevision, error) {
+	o := options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.D{{"created_at", -1}})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{"post_id": postID}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := make([]*PostRevision, 0)
+	for cursor.Next(ctx) {
+		var revision PostRevision
+		if err := cursor.Decode(&revision); err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, nil
+}
+
+func (dao *mongoRevisionDAO) Create(ctx context.Context, revision *PostRevision) (primitive.ObjectID, error) {
+	result, err := dao.collection.InsertOne(ctx, revision)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	revision.ID = result.InsertedID.(primitive.ObjectID)
+
+	return revision.ID, nil
+}
+
+func (dao *mongoRevisionDAO) DeleteByPostID(ctx context.Context, postID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"post_id": postID})
+	return err
+}
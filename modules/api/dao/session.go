@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a login of a user on one device, it holds the hash of the current refresh token.
+type Session struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	TokenHash string             `bson:"token_hash,omitempty"`
+	// PreviousTokenHash is the hash of the refresh token rotated away last, presenting it again means it was stolen.
+	PreviousTokenHash string    `bson:"previous_token_hash,omitempty"`
+	ExpiresAT         time.Time `bson:"expires_at,omitempty"`
+	CreatedAT         time.Time `bson:"created_at,omitempty"`
+	UpdatedAT         time.Time `bson:"updated_at,omitempty"`
+}
+
+type SessionDAO interface {
+	Create(ctx context.Context, session *Session) (primitive.ObjectID, error)
+	// Rotate replaces the refresh token of the unexpired session holding tokenHash.
+	Rotate(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*Session, error)
+	GetByPreviousTokenHash(ctx context.Context, tokenHash string) (*Session, error)
+	ListIDsByUserID(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
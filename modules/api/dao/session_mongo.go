@@ -0,0 +1,145 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessionDAO struct {
+	collection *mongo.Collection
+}
+
+var _ SessionDAO = (*mongoSessionDAO)(nil)
+
+func NewMongoSessionDAO(collection *mongo.Collection) *mongoSessionDAO {
+	return &mongoSessionDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoSessionDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"token_hash", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{"previous_token_hash", 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{{"user_id", 1}},
+		},
+		{
+			// expired sessions are removed by MongoDB
+			Keys:    bson.D{{"expires_at", 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoSessionDAO) Create(ctx context.Context, session *Session) (primitive.ObjectID, error) {
+	result, err := dao.collection.InsertOne(ctx, session)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	session.ID = result.InsertedID.(primitive.ObjectID)
+
+	return session.ID, nil
+}
+
+func (dao *mongoSessionDAO) Rotate(
+	ctx context.Context,
+	tokenHash, newTokenHash string,
+	expiresAt time.Time,
+) (*Session, error) {
+	now := time.Now()
+
+	var session Session
+	o := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := dao.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": tokenHash,
+			// the TTL monitor only runs once a minute
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"token_hash":          newTokenHash,
+				"previous_token_hash": tokenHash,
+				"expires_at":          expiresAt,
+				"updated_at":          now,
+			},
+		},
+		o,
+	).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (dao *mongoSessionDAO) GetByPreviousTokenHash(ctx context.Context, tokenHash string) (*Session, error) {
+	var session Session
+	if err := dao.collection.FindOne(ctx, bson.M{"previous_token_hash": tokenHash}).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (dao *mongoSessionDAO) ListIDsByUserID(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	o := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{"user_id": userID}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := make([]primitive.ObjectID, 0)
+	for cursor.Next(ctx) {
+		var session Session
+		if err := cursor.Decode(&session); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, session.ID)
+	}
+
+	return ids, nil
+}
+
+func (dao *mongoSessionDAO) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if result, err := dao.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return err
+	} else if result.DeletedCount == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoSessionDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
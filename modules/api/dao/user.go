@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -19,31 +20,109 @@ type User struct {
 	FB          string             `bson:"fb,omitempty"`
 	TW          string             `bson:"tw,omitempty"`
 	Email       string             `bson:"email,omitempty"`
+	// EmailVerified is reset whenever the email changes
+	EmailVerified bool         `bson:"email_verified,omitempty"`
+	Followers     int          `bson:"followers,omitempty"`
+	Following     int          `bson:"following,omitempty"`
+	Role          authkit.Role `bson:"role,omitempty"`
+	// TOTPSecret is set on enrollment, it is only used for logins once TOTPEnabled
+	TOTPSecret  string `bson:"totp_secret,omitempty"`
+	TOTPEnabled bool   `bson:"totp_enabled,omitempty"`
+	// TOTPCounter is the last time step whose code was used, codes can not be used twice
+	TOTPCounter int64 `bson:"totp_counter,omitempty"`
+	// RecoveryCodes are the hashes of the unused recovery codes
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"`
+}
+
+var userRoleToProto = map[authkit.Role]pb.UserRole{
+	authkit.RoleReader: pb.UserRole_USER_ROLE_READER,
+	authkit.RoleAuthor: pb.UserRole_USER_ROLE_AUTHOR,
+	authkit.RoleEditor: pb.UserRole_USER_ROLE_EDITOR,
+	authkit.RoleAdmin:  pb.UserRole_USER_ROLE_ADMIN,
+}
+
+// UserRoleFromProto converts the role of a request, it returns an empty role if unspecified.
+func UserRoleFromProto(role pb.UserRole) authkit.Role {
+	for r, p := range userRoleToProto {
+		if p == role {
+			return r
+		}
+	}
+
+	return ""
+}
+
+// GetRole returns the role of the user, users created before roles existed are authors.
+func (u *User) GetRole() authkit.Role {
+	if u.Role == "" {
+		return authkit.RoleAuthor
+	}
+
+	return u.Role
 }
 
 type UserDAO interface {
 	Get(ctx context.Context, id primitive.ObjectID) (*User, error)
+	// GetMany returns the users found among ids in a single round trip, missing users are skipped.
+	GetMany(ctx context.Context, ids []primitive.ObjectID) ([]*User, error)
 	GetByUserAccount(ctx context.Context, account string) (*User, error)
-	List(ctx context.Context, limit, skip int64) ([]*User, error)
+	// List returns the users in creation order, or ranked by relevance if filter is not empty.
+	// See PostDAO.List for the cursor.
+	List(ctx context.Context, limit, skip int64, filter string, cursor *PageCursor) ([]*User, *PageCursor, error)
+	// Create returns ErrAccountExists or ErrEmailExists if another user has the account or the email.
 	Create(ctx context.Context, user *User) error
+	// Update returns ErrEmailExists if another user has the email.
 	Update(ctx context.Context, user *User) error
+	// VerifyEmail marks the email of the user verified, it returns ErrUserNotFound if the user has another email by now.
+	VerifyEmail(ctx context.Context, id primitive.ObjectID, email string) error
+	// UpdateFollowCounts adjusts the denormalized follower and following counters of the user.
+	UpdateFollowCounts(ctx context.Context, id primitive.ObjectID, followers, following int) error
+	UpdateRole(ctx context.Context, id primitive.ObjectID, role authkit.Role) error
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error
+	// SetTOTPSecret stores the secret of a pending enrollment, it fails if two-factor authentication is enabled.
+	SetTOTPSecret(ctx context.Context, id primitive.ObjectID, secret string) error
+	EnableTOTP(ctx context.Context, id primitive.ObjectID, counter int64, recoveryCodes []string) error
+	DisableTOTP(ctx context.Context, id primitive.ObjectID) error
+	// UseTOTPCounter records counter as the last used time step, it returns false if it was not after the last one.
+	UseTOTPCounter(ctx context.Context, id primitive.ObjectID, counter int64) (bool, error)
+	// UseRecoveryCode removes the recovery code, it returns false if the user has no such code.
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
 }
 
 func (u *User) ToProto() *pb.UserInfo {
 	return &pb.UserInfo{
-		UserId:      u.ID.Hex(),
-		UserName:    u.Name,
-		UserAccount: u.Account,
-		Description: u.Description,
-		Avator:      u.Avator,
-		Ig:          u.IG,
-		Fb:          u.FB,
-		Tw:          u.TW,
-		Email:       u.Email,
+		UserId:         u.ID.Hex(),
+		UserName:       u.Name,
+		UserAccount:    u.Account,
+		Description:    u.Description,
+		Avator:         u.Avator,
+		Ig:             u.IG,
+		Fb:             u.FB,
+		Tw:             u.TW,
+		Email:          u.Email,
+		EmailVerified:  u.EmailVerified,
+		FollowersCount: uint32(u.Followers),
+		FollowingCount: uint32(u.Following),
+		Role:           userRoleToProto[u.GetRole()],
 	}
 }
 
+// UserMigration reports the emails lowercased by the migration of the users, the users made searchable by
+// prefix, and the users sharing an account or an email which block the unique indexes. Conflicting users are
+// left for the operator to resolve.
+type UserMigration struct {
+	Normalized       []*User
+	Searchable       []*User
+	AccountConflicts [][]*User
+	EmailConflicts   [][]*User
+}
+
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrAccountExists = errors.New("account already exists")
+	ErrEmailExists   = errors.New("email already exists")
+	// ErrDuplicateUsers is returned by CreateIndex when existing users share an account or an email.
+	ErrDuplicateUsers = errors.New("users share an account or an email, run `api migrate-users` to list and fix them")
 )
@@ -3,17 +3,46 @@ package dao
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/alice890308/blog-server/pkg/authkit"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// userPrefixScore is added to the text score of users whose name or account starts with the search filter.
+const userPrefixScore = 2.0
+
+// names of the unique indexes, which tell apart the duplicate key errors
+const (
+	userAccountIndex = "account_unique"
+	userEmailIndex   = "email_unique"
+)
+
 type mongoUserDAO struct {
 	collection *mongo.Collection
 }
 
+// searchableUser is a stored user with the lowercased copies of its account and name, which the prefix search
+// matches case-sensitively so that it can use their indexes.
+type searchableUser struct {
+	User         `bson:",inline"`
+	AccountLower string `bson:"account_lower,omitempty"`
+	NameLower    string `bson:"name_lower,omitempty"`
+}
+
+func newSearchableUser(user *User) *searchableUser {
+	return &searchableUser{
+		User:         *user,
+		AccountLower: strings.ToLower(user.Account),
+		NameLower:    strings.ToLower(user.Name),
+	}
+}
+
 var _ UserDAO = (*mongoUserDAO)(nil)
 
 func NewMongoUserDAO(collection *mongo.Collection) *mongoUserDAO {
@@ -22,6 +51,154 @@ func NewMongoUserDAO(collection *mongo.Collection) *mongoUserDAO {
 	}
 }
 
+func (dao *mongoUserDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys: bson.D{{"name", "text"}, {"account", "text"}, {"description", "text"}},
+			Options: options.Index().
+				SetWeights(bson.D{{"account", 10}, {"name", 5}, {"description", 1}}).
+				// names and accounts are not words of a language, don't stem them
+				SetDefaultLanguage("none"),
+		},
+		{
+			Keys:    bson.D{{"account", 1}},
+			Options: options.Index().SetName(userAccountIndex).SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"account_lower", 1}},
+		},
+		{
+			Keys: bson.D{{"name_lower", 1}},
+		},
+		{
+			// users without an email are left out
+			Keys: bson.D{{"email", 1}},
+			Options: options.Index().
+				SetName(userEmailIndex).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		// users created before the unique indexes may share an email differing only in case
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: %v", ErrDuplicateUsers, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Migrate lowercases the emails stored before they were normalized and finds the users sharing an account or
+// an email. Users whose lowercased emails collide are not changed. The users stored before the prefix search
+// get the lowercased copies of their account and name. A dry run only reports.
+func (dao *mongoUserDAO) Migrate(ctx context.Context, dryRun bool) (*UserMigration, error) {
+	o := options.Find().
+		SetProjection(bson.M{"account": 1, "name": 1, "email": 1, "email_verified": 1, "account_lower": 1, "name_lower": 1}).
+		SetSort(bson.D{{"_id", 1}})
+
+	cursor, err := dao.collection.Find(ctx, bson.M{}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	migration := &UserMigration{}
+	var accounts, emails []string
+	byAccount := make(map[string][]*User)
+	byEmail := make(map[string][]*User)
+	for cursor.Next(ctx) {
+		var stored searchableUser
+		if err := cursor.Decode(&stored); err != nil {
+			return nil, err
+		}
+		user := &stored.User
+
+		if searchable := newSearchableUser(user); stored.AccountLower != searchable.AccountLower || stored.NameLower != searchable.NameLower {
+			migration.Searchable = append(migration.Searchable, user)
+			if !dryRun {
+				_, err := dao.collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.D{{"$set", bson.D{
+					{"account_lower", searchable.AccountLower},
+					{"name_lower", searchable.NameLower},
+				}}})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if _, ok := byAccount[user.Account]; !ok {
+			accounts = append(accounts, user.Account)
+		}
+		byAccount[user.Account] = append(byAccount[user.Account], user)
+
+		email := strings.ToLower(strings.TrimSpace(user.Email))
+		if email == "" {
+			continue
+		}
+		if _, ok := byEmail[email]; !ok {
+			emails = append(emails, email)
+		}
+		byEmail[email] = append(byEmail[email], user)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		if len(byAccount[account]) > 1 {
+			migration.AccountConflicts = append(migration.AccountConflicts, byAccount[account])
+		}
+	}
+
+	for _, email := range emails {
+		users := byEmail[email]
+		if len(users) > 1 {
+			migration.EmailConflicts = append(migration.EmailConflicts, users)
+			continue
+		}
+
+		user := users[0]
+		if user.Email == email {
+			continue
+		}
+		migration.Normalized = append(migration.Normalized, user)
+		if dryRun {
+			continue
+		}
+
+		// only the stored spelling changes, so the email stays verified
+		_, err := dao.collection.UpdateOne(ctx, bson.M{"_id": user.ID, "email": user.Email}, bson.D{{"$set", bson.D{{"email", email}}}})
+		if err != nil {
+			return nil, duplicateKeyError(err)
+		}
+	}
+
+	return migration, nil
+}
+
+// duplicateKeyError maps a violation of a unique index to the error of the index.
+func duplicateKeyError(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	switch {
+	case strings.Contains(err.Error(), userAccountIndex):
+		return ErrAccountExists
+	case strings.Contains(err.Error(), userEmailIndex):
+		return ErrEmailExists
+	default:
+		return err
+	}
+}
+
+// secretsProjection leaves out the credentials of users listed to others.
+func secretsProjection() bson.M {
+	return bson.M{"password": 0, "totp_secret": 0, "recovery_codes": 0}
+}
+
 func (dao *mongoUserDAO) Get(ctx context.Context, id primitive.ObjectID) (*User, error) {
 	var user User
 	if err := dao.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
@@ -35,6 +212,32 @@ func (dao *mongoUserDAO) Get(ctx context.Context, id primitive.ObjectID) (*User,
 	return &user, nil
 }
 
+func (dao *mongoUserDAO) GetMany(ctx context.Context, ids []primitive.ObjectID) ([]*User, error) {
+	if len(ids) == 0 {
+		return []*User{}, nil
+	}
+
+	o := options.Find().SetProjection(secretsProjection())
+
+	cursor, err := dao.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]*User, 0, len(ids))
+	for cursor.Next(ctx) {
+		var user User
+		if err := cursor.Decode(&user); err != nil {
+			return nil, err
+		}
+
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
 func (dao *mongoUserDAO) GetByUserAccount(ctx context.Context, account string) (*User, error) {
 	var user User
 	if err := dao.collection.FindOne(ctx, bson.M{"account": account}).Decode(&user); err != nil {
@@ -48,32 +251,132 @@ func (dao *mongoUserDAO) GetByUserAccount(ctx context.Context, account string) (
 	return &user, nil
 }
 
-func (dao *mongoUserDAO) List(ctx context.Context, limit, skip int64) ([]*User, error) {
-	o := options.Find().SetLimit(limit).SetSkip(skip)
+func (dao *mongoUserDAO) List(ctx context.Context, limit, skip int64, filter string, cursor *PageCursor) ([]*User, *PageCursor, error) {
+	if filter != "" {
+		return dao.search(ctx, limit, skip, filter, cursor)
+	}
 
-	cursor, err := dao.collection.Find(ctx, bson.M{}, o)
+	f := bson.M{}
+	if cursor != nil {
+		after, err := cursor.afterID()
+		if err != nil {
+			return nil, nil, err
+		}
+		f = after
+		skip = 0
+	}
+
+	// fetch one more user to know whether there is a next page
+	o := options.Find().
+		SetLimit(limit + 1).
+		SetSkip(skip).
+		SetSort(bson.D{{"_id", 1}}).
+		SetProjection(secretsProjection())
+
+	c, err := dao.collection.Find(ctx, f, o)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer cursor.Close(ctx)
+	defer c.Close(ctx)
 
 	users := make([]*User, 0)
-	for cursor.Next(ctx) {
+	for c.Next(ctx) {
 		var user User
-		if err := cursor.Decode((&user)); err != nil {
-			return nil, err
+		if err := c.Decode((&user)); err != nil {
+			return nil, nil, err
 		}
 
 		users = append(users, &user)
 	}
 
-	return users, nil
+	if int64(len(users)) <= limit {
+		return users, nil, nil
+	}
+
+	users = users[:limit]
+
+	return users, newIDCursor(users[len(users)-1].ID), nil
+}
+
+// search ranks users by the full-text score over name, account and description, plus a bonus for
+// names and accounts starting with the filter since the text index only matches whole words. The prefix
+// is matched case-sensitively on the lowercased copies, an anchored regex without options scans only the
+// range of their indexes.
+func (dao *mongoUserDAO) search(ctx context.Context, limit, skip int64, filter string, cursor *PageCursor) ([]*User, *PageCursor, error) {
+	prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.ToLower(filter))}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"$text": bson.M{"$search": filter}}}},
+		{{"$addFields", bson.M{"score": bson.M{"$meta": "textScore"}}}},
+		{{"$unionWith", bson.M{
+			"coll": dao.collection.Name(),
+			"pipeline": mongo.Pipeline{
+				{{"$match", bson.M{"$or": bson.A{
+					bson.M{"account_lower": prefix},
+					bson.M{"name_lower": prefix},
+				}}}},
+				{{"$addFields", bson.M{"score": userPrefixScore}}},
+			},
+		}}},
+		// users matching both ways are merged with the sum of their scores
+		{{"$group", bson.M{
+			"_id":   "$_id",
+			"user":  bson.M{"$first": "$$ROOT"},
+			"score": bson.M{"$sum": "$score"},
+		}}},
+		{{"$replaceRoot", bson.M{"newRoot": bson.M{"$mergeObjects": bson.A{"$user", bson.M{"score": "$score"}}}}}},
+	}
+	if cursor != nil {
+		after, err := cursor.afterScore()
+		if err != nil {
+			return nil, nil, err
+		}
+		pipeline = append(pipeline, bson.D{{"$match", after}})
+		skip = 0
+	}
+	pipeline = append(pipeline,
+		bson.D{{"$sort", bson.D{{"score", -1}, {"_id", -1}}}},
+		bson.D{{"$skip", skip}},
+		// fetch one more user to know whether there is a next page
+		bson.D{{"$limit", limit + 1}},
+		bson.D{{"$project", secretsProjection()}},
+	)
+
+	c, err := dao.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer c.Close(ctx)
+
+	var scores []float64
+	users := make([]*User, 0)
+	for c.Next(ctx) {
+		var user struct {
+			User  `bson:",inline"`
+			Score float64 `bson:"score"`
+		}
+		if err := c.Decode(&user); err != nil {
+			return nil, nil, err
+		}
+
+		users = append(users, &user.User)
+		scores = append(scores, user.Score)
+	}
+
+	if int64(len(users)) <= limit {
+		return users, nil, nil
+	}
+
+	users = users[:limit]
+	last := users[len(users)-1]
+
+	return users, newScoreCursor(scores[len(users)-1], last.ID), nil
 }
 
 func (dao *mongoUserDAO) Create(ctx context.Context, user *User) error {
-	result, err := dao.collection.InsertOne(ctx, user)
+	result, err := dao.collection.InsertOne(ctx, newSearchableUser(user))
 	if err != nil {
-		return err
+		return duplicateKeyError(err)
 	}
 
 	user.ID = result.InsertedID.(primitive.ObjectID)
@@ -87,18 +390,201 @@ func (dao *mongoUserDAO) Update(ctx context.Context, user *User) error {
 		user.ID,
 		bson.M{
 			"$set": bson.M{
-				"name":        user.Name,
-				"description": user.Description,
-				"avator":      user.Avator,
-				"ig":          user.IG,
-				"fb":          user.FB,
-				"tw":          user.TW,
-				"email":       user.Email,
+				"name":           user.Name,
+				"name_lower":     strings.ToLower(user.Name),
+				"description":    user.Description,
+				"avator":         user.Avator,
+				"ig":             user.IG,
+				"fb":             user.FB,
+				"tw":             user.TW,
+				"email":          user.Email,
+				"email_verified": user.EmailVerified,
+			},
+		},
+	); err != nil {
+		return duplicateKeyError(err)
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) VerifyEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	if result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":   id,
+			"email": email,
+		},
+		bson.M{
+			"$set": bson.M{
+				"email_verified": true,
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) UpdateRole(ctx context.Context, id primitive.ObjectID, role authkit.Role) error {
+	if result, err := dao.collection.UpdateByID(
+		ctx,
+		id,
+		bson.M{
+			"$set": bson.M{
+				"role": role,
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	if result, err := dao.collection.UpdateByID(
+		ctx,
+		id,
+		bson.M{
+			"$set": bson.M{
+				"password": password,
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) SetTOTPSecret(ctx context.Context, id primitive.ObjectID, secret string) error {
+	if result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":          id,
+			"totp_enabled": bson.M{"$ne": true},
+		},
+		bson.M{
+			"$set": bson.M{
+				"totp_secret": secret,
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) EnableTOTP(ctx context.Context, id primitive.ObjectID, counter int64, recoveryCodes []string) error {
+	if result, err := dao.collection.UpdateByID(
+		ctx,
+		id,
+		bson.M{
+			"$set": bson.M{
+				"totp_enabled":   true,
+				"totp_counter":   counter,
+				"recovery_codes": recoveryCodes,
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) DisableTOTP(ctx context.Context, id primitive.ObjectID) error {
+	if result, err := dao.collection.UpdateByID(
+		ctx,
+		id,
+		bson.M{
+			"$unset": bson.M{
+				"totp_secret":    "",
+				"totp_enabled":   "",
+				"totp_counter":   "",
+				"recovery_codes": "",
+			},
+		},
+	); err != nil {
+		return err
+	} else if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (dao *mongoUserDAO) UseTOTPCounter(ctx context.Context, id primitive.ObjectID, counter int64) (bool, error) {
+	result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": id,
+			"$or": bson.A{
+				bson.M{"totp_counter": bson.M{"$lt": counter}},
+				bson.M{"totp_counter": bson.M{"$exists": false}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{
+				"totp_counter": counter,
+			},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (dao *mongoUserDAO) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error) {
+	result, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":            id,
+			"recovery_codes": codeHash,
+		},
+		bson.M{
+			"$pull": bson.M{
+				"recovery_codes": codeHash,
+			},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (dao *mongoUserDAO) UpdateFollowCounts(ctx context.Context, id primitive.ObjectID, followers, following int) error {
+	if result, err := dao.collection.UpdateByID(
+		ctx,
+		id,
+		bson.M{
+			"$inc": bson.M{
+				"followers": followers,
+				"following": following,
 			},
 		},
 	); err != nil {
 		return err
-	} else if result.ModifiedCount == 0 {
+	} else if result.MatchedCount == 0 {
 		return ErrUserNotFound
 	}
 
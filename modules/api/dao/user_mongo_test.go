@@ -0,0 +1,104 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoUserDAOSearchMatchesLowercasedPrefix(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("search", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "blog.users", mtest.FirstBatch))
+
+		if _, _, err := NewMongoUserDAO(mt.Coll).List(context.Background(), 10, 0, "Al.Ice", nil); err != nil {
+			mt.Fatal(err)
+		}
+
+		stages, err := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		if err != nil {
+			mt.Fatal(err)
+		}
+		var union bson.Raw
+		for _, stage := range stages {
+			if value, err := stage.Document().LookupErr("$unionWith", "pipeline"); err == nil {
+				union = value.Array()
+			}
+		}
+		if union == nil {
+			mt.Fatal("the search does not match prefixes")
+		}
+
+		match, err := union.Index(0).Value().Document().LookupErr("$match", "$or")
+		if err != nil {
+			mt.Fatal(err)
+		}
+		conditions, err := match.Array().Values()
+		if err != nil {
+			mt.Fatal(err)
+		}
+		fields := make(map[string]bool)
+		for _, condition := range conditions {
+			element := condition.Document().Index(0)
+			pattern, options, ok := element.Value().RegexOK()
+			if !ok {
+				mt.Fatalf("%s is not matched by a regex", element.Key())
+			}
+			// an option such as i would scan the whole index
+			if pattern != `^al\.ice` || options != "" {
+				mt.Errorf("%s is matched by /%s/%s, want /^al\\.ice/", element.Key(), pattern, options)
+			}
+			fields[element.Key()] = true
+		}
+		if len(fields) != 2 || !fields["account_lower"] || !fields["name_lower"] {
+			mt.Errorf("the prefix is matched on %v, want the lowercased account and name", fields)
+		}
+	})
+}
+
+func TestMongoUserDAOStoresLowercasedCopies(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("create", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		user := &User{Account: "Alice", Name: "Alice Liddell"}
+		if err := NewMongoUserDAO(mt.Coll).Create(context.Background(), user); err != nil {
+			mt.Fatal(err)
+		}
+		if user.ID.IsZero() {
+			mt.Error("the created user has no ID")
+		}
+
+		doc := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		for field, want := range map[string]string{
+			"account":       "Alice",
+			"name":          "Alice Liddell",
+			"account_lower": "alice",
+			"name_lower":    "alice liddell",
+		} {
+			if got, ok := doc.Lookup(field).StringValueOK(); !ok || got != want {
+				mt.Errorf("stored %s %q, want %q", field, got, want)
+			}
+		}
+	})
+
+	mt.Run("update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}))
+
+		if err := NewMongoUserDAO(mt.Coll).Update(context.Background(), &User{ID: primitive.NewObjectID(), Name: "Mad Hatter"}); err != nil {
+			mt.Fatal(err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if got, ok := update.Lookup("u", "$set", "name_lower").StringValueOK(); !ok || got != "mad hatter" {
+			mt.Errorf("stored name_lower %q, want %q", got, "mad hatter")
+		}
+	})
+}
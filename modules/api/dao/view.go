@@ -0,0 +1,17 @@
+package dao
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ViewDAO interface {
+	// Record counts a view of the post unless the viewer has already been counted within the dedup window,
+	// it returns whether the view was counted.
+	Record(ctx context.Context, postID primitive.ObjectID, viewer string) (bool, error)
+	// PopPending returns the views counted since the last call and resets them.
+	PopPending(ctx context.Context) (map[primitive.ObjectID]int, error)
+	// PushPending gives views back, e.g. when they failed to be flushed.
+	PushPending(ctx context.Context, postID primitive.ObjectID, views int) error
+}
@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	viewPendingKey    = "views:pending"
+	viewSeenKeyPrefix = "views:seen:"
+)
+
+type redisViewDAO struct {
+	client *redis.Client
+	window time.Duration
+}
+
+var _ ViewDAO = (*redisViewDAO)(nil)
+
+func NewRedisViewDAO(client *redis.Client, window time.Duration) *redisViewDAO {
+	return &redisViewDAO{
+		client: client,
+		window: window,
+	}
+}
+
+func (dao *redisViewDAO) Record(ctx context.Context, postID primitive.ObjectID, viewer string) (bool, error) {
+	key := viewSeenKeyPrefix + postID.Hex() + ":" + viewer
+
+	// the seen key expires after the window, so the same viewer counts again afterwards
+	ok, err := dao.client.SetNX(ctx, key, 1, dao.window).Result()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if err := dao.client.HIncrBy(ctx, viewPendingKey, postID.Hex(), 1).Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (dao *redisViewDAO) PopPending(ctx context.Context) (map[primitive.ObjectID]int, error) {
+	var pending *redis.StringStringMapCmd
+	if _, err := dao.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pending = pipe.HGetAll(ctx, viewPendingKey)
+		pipe.Del(ctx, viewPendingKey)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	views := make(map[primitive.ObjectID]int, len(pending.Val()))
+	for field, value := range pending.Val() {
+		postID, err := primitive.ObjectIDFromHex(field)
+		if err != nil {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		views[postID] = n
+	}
+
+	return views, nil
+}
+
+func (dao *redisViewDAO) PushPending(ctx context.Context, postID primitive.ObjectID, views int) error {
+	return dao.client.HIncrBy(ctx, viewPendingKey, postID.Hex(), int64(views)).Err()
+}
@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (h *Handler) buildAtom(q query, posts []*pb.PostInfo, selfURL string, updated time.Time) *atomFeed {
+	// an empty feed still needs an updated time
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	feed := &atomFeed{
+		Title:    h.title(q, posts),
+		Subtitle: h.conf.Description,
+		ID:       selfURL,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: h.siteLink(q), Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, 0, len(posts)),
+	}
+
+	base := strings.TrimSuffix(h.conf.SiteURL, "/")
+	for _, post := range posts {
+		link := h.postLink(post)
+
+		categories := make([]atomCategory, 0, len(post.GetTags()))
+		for _, tag := range post.GetTags() {
+			categories = append(categories, atomCategory{Term: tag})
+		}
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     post.GetTitle(),
+			ID:        link,
+			Updated:   post.GetUpdatedAt().AsTime().UTC().Format(time.RFC3339),
+			Published: publishedAt(post).UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Author: atomPerson{
+				Name: post.GetUserName(),
+				URI:  base + "/users/" + post.GetUserId(),
+			},
+			Categories: categories,
+			Content:    atomContent{Type: "text", Value: post.GetContent()},
+		})
+	}
+
+	return feed
+}
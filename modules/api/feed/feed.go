@@ -0,0 +1,243 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// PathPrefix is where the feeds are served:
+//
+//	/feeds/posts.{rss,atom}
+//	/feeds/users/{user_id}/posts.{rss,atom}
+//	/feeds/tags/{tag}/posts.{rss,atom}
+const PathPrefix = "/feeds/"
+
+type FeedConfig struct {
+	SiteURL     string `long:"site_url" env:"SITE_URL" description:"base URL of the blog, used to link posts and authors and as the id of the feeds" default:"http://localhost:3000"`
+	Title       string `long:"title" env:"TITLE" description:"title of the feeds" default:"Blog"`
+	Description string `long:"description" env:"DESCRIPTION" description:"description of the feeds" default:"Latest posts"`
+	Size        int64  `long:"size" env:"SIZE" description:"number of posts in a feed" default:"20"`
+}
+
+type format int8
+
+const (
+	formatRSS format = iota
+	formatAtom
+)
+
+// query describes which posts a feed contains.
+type query struct {
+	format format
+	userID string
+	tag    string
+}
+
+type Handler struct {
+	client pb.PostClient
+	conf   *FeedConfig
+	logger *logkit.Logger
+}
+
+func NewHandler(client pb.PostClient, conf *FeedConfig, logger *logkit.Logger) *Handler {
+	return &Handler{
+		client: client,
+		conf:   conf,
+		logger: logger,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, ok := parsePath(strings.TrimPrefix(r.URL.Path, PathPrefix))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	posts, err := h.listPosts(r, q)
+	if err != nil {
+		h.logger.Error("failed to list posts of feed", zap.String("path", r.URL.Path), zap.Error(err))
+		code := runtime.HTTPStatusFromCode(status.Code(err))
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	var updated time.Time
+	for _, post := range posts {
+		if t := post.GetUpdatedAt().AsTime(); t.After(updated) {
+			updated = t
+		}
+	}
+
+	selfURL := h.feedLink(r)
+	var doc interface{}
+	var contentType string
+	switch q.format {
+	case formatRSS:
+		doc = h.buildRSS(q, posts, selfURL, updated)
+		contentType = "application/rss+xml; charset=utf-8"
+	case formatAtom:
+		doc = h.buildAtom(q, posts, selfURL, updated)
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	enc := xml.NewEncoder(&body)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		h.logger.Error("failed to encode feed", zap.String("path", r.URL.Path), zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	if !updated.IsZero() {
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write(body.Bytes())
+}
+
+func (h *Handler) listPosts(r *http.Request, q query) ([]*pb.PostInfo, error) {
+	if q.userID != "" {
+		resp, err := h.client.ListPostByUserID(r.Context(), &pb.ListPostByUserIDRequest{
+			UserId: q.userID,
+			Limit:  h.conf.Size,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return resp.GetPosts(), nil
+	}
+
+	req := &pb.ListPostRequest{Limit: h.conf.Size}
+	if q.tag != "" {
+		req.Tag = proto.String(q.tag)
+	}
+
+	resp, err := h.client.ListPost(r.Context(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetPosts(), nil
+}
+
+func (h *Handler) title(q query, posts []*pb.PostInfo) string {
+	switch {
+	case q.userID != "" && len(posts) > 0:
+		return h.conf.Title + " - " + posts[0].GetUserName()
+	case q.tag != "":
+		return h.conf.Title + " - #" + q.tag
+	default:
+		return h.conf.Title
+	}
+}
+
+// siteLink is the page of the blog showing the same posts as the feed.
+func (h *Handler) siteLink(q query) string {
+	base := strings.TrimSuffix(h.conf.SiteURL, "/")
+
+	switch {
+	case q.userID != "":
+		return base + "/users/" + q.userID
+	case q.tag != "":
+		return base + "/tags/" + q.tag
+	default:
+		return base + "/"
+	}
+}
+
+// feedLink is the URL of the feed itself, built from the configured site rather than the request headers
+// so that the id of a feed does not change with the host or the proxy it was fetched through.
+func (h *Handler) feedLink(r *http.Request) string {
+	return strings.TrimSuffix(h.conf.SiteURL, "/") + r.URL.EscapedPath()
+}
+
+func (h *Handler) postLink(post *pb.PostInfo) string {
+	return strings.TrimSuffix(h.conf.SiteURL, "/") + "/posts/" + post.GetPostId()
+}
+
+func parsePath(path string) (query, bool) {
+	var q query
+
+	segments := strings.Split(path, "/")
+	switch segments[len(segments)-1] {
+	case "posts.rss":
+		q.format = formatRSS
+	case "posts.atom":
+		q.format = formatAtom
+	default:
+		return q, false
+	}
+
+	switch {
+	case len(segments) == 1:
+	case len(segments) == 3 && segments[0] == "users" && segments[1] != "":
+		q.userID = segments[1]
+	case len(segments) == 3 && segments[0] == "tags" && segments[1] != "":
+		q.tag = segments[1]
+	default:
+		return q, false
+	}
+
+	return q, true
+}
+
+// notModified evaluates the conditional request headers, If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, updated time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !updated.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		return !updated.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
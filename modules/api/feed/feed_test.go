@@ -0,0 +1,70 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"google.golang.org/grpc"
+)
+
+// fakePostClient only lists posts, calling any other method panics on the nil embedded interface.
+type fakePostClient struct {
+	pb.PostClient
+}
+
+func (f *fakePostClient) ListPost(ctx context.Context, in *pb.ListPostRequest, opts ...grpc.CallOption) (*pb.ListPostResponse, error) {
+	return &pb.ListPostResponse{}, nil
+}
+
+func (f *fakePostClient) ListPostByUserID(ctx context.Context, in *pb.ListPostByUserIDRequest, opts ...grpc.CallOption) (*pb.ListPostByUserIDResponse, error) {
+	return &pb.ListPostByUserIDResponse{}, nil
+}
+
+func TestFeedLinkIgnoresRequestHeaders(t *testing.T) {
+	h := NewHandler(&fakePostClient{}, &FeedConfig{SiteURL: "https://blog.example/", Title: "Blog", Size: 20}, logkit.NewLogger(&logkit.LoggerConfig{}))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{
+			path: "/feeds/posts.atom",
+			want: []string{
+				"<id>https://blog.example/feeds/posts.atom</id>",
+				`<link href="https://blog.example/feeds/posts.atom" rel="self"`,
+			},
+		},
+		{
+			path: "/feeds/tags/go/posts.rss",
+			want: []string{`<atom:link href="https://blog.example/feeds/tags/go/posts.rss" rel="self"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://evil.example"+test.path, nil)
+			r.Header.Set("X-Forwarded-Proto", "gopher")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("responded %d, want %d", w.Code, http.StatusOK)
+			}
+			body := w.Body.String()
+			for _, want := range test.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("feed does not contain %s:\n%s", want, body)
+				}
+			}
+			if strings.Contains(body, "evil.example") || strings.Contains(body, "gopher") {
+				t.Errorf("feed links to the request host or scheme:\n%s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+)
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (h *Handler) buildRSS(q query, posts []*pb.PostInfo, selfURL string, updated time.Time) *rssDocument {
+	channel := rssChannel{
+		Title:       h.title(q, posts),
+		Link:        h.siteLink(q),
+		Description: h.conf.Description,
+		AtomLink: rssAtomLink{
+			Href: selfURL,
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+		Items: make([]rssItem, 0, len(posts)),
+	}
+	if !updated.IsZero() {
+		channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, post := range posts {
+		link := h.postLink(post)
+		channel.Items = append(channel.Items, rssItem{
+			Title:       post.GetTitle(),
+			Link:        link,
+			Description: post.GetContent(),
+			Categories:  post.GetTags(),
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     publishedAt(post).UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return &rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	}
+}
+
+// publishedAt falls back to the creation time for posts published before publish_at existed.
+func publishedAt(post *pb.PostInfo) time.Time {
+	if post.GetPublishAt() != nil {
+		return post.GetPublishAt().AsTime()
+	}
+
+	return post.GetCreatedAt().AsTime()
+}
@@ -0,0 +1,190 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// PathPrefix is where the OpenID Connect login is served:
+//
+//	/oidc/login     redirects to the provider
+//	/oidc/callback  receives the authorization code and signs the user in
+const PathPrefix = "/oidc/"
+
+const (
+	// flowCookie keeps the state, nonce and PKCE code verifier of a login in progress
+	flowCookie = "oidc_flow"
+	flowMaxAge = 10 * time.Minute
+	// flowTimeout bounds the exchange with the provider and the API
+	flowTimeout = 15 * time.Second
+)
+
+type Handler struct {
+	provider *authkit.OIDCProvider
+	client   pb.SessionClient
+	conf     *authkit.OIDCConfig
+	logger   *logkit.Logger
+}
+
+func NewHandler(provider *authkit.OIDCProvider, client pb.SessionClient, conf *authkit.OIDCConfig, logger *logkit.Logger) *Handler {
+	return &Handler{
+		provider: provider,
+		client:   client,
+		conf:     conf,
+		logger:   logger,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, PathPrefix) {
+	case "login":
+		h.login(w, r)
+	case "callback":
+		h.callback(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
+	state, err := authkit.NewOIDCState()
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, "failed to create state", err)
+		return
+	}
+	nonce, err := authkit.NewOIDCState()
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, "failed to create nonce", err)
+		return
+	}
+	verifier, challenge, err := authkit.NewPKCE()
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, "failed to create code verifier", err)
+		return
+	}
+
+	authURL, err := h.provider.AuthCodeURL(r.Context(), state, nonce, challenge)
+	if err != nil {
+		h.fail(w, r, http.StatusBadGateway, "failed to build authorization URL", err)
+		return
+	}
+
+	h.setFlowCookie(w, strings.Join([]string{state, nonce, verifier}, "."), int(flowMaxAge.Seconds()))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *Handler) callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	cookie, err := r.Cookie(flowCookie)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, "no login in progress", err)
+		return
+	}
+	// the flow can only be completed once
+	h.setFlowCookie(w, "", -1)
+
+	flow := strings.Split(cookie.Value, ".")
+	if len(flow) != 3 || subtle.ConstantTimeCompare([]byte(flow[0]), []byte(q.Get("state"))) != 1 {
+		h.fail(w, r, http.StatusBadRequest, "state mismatch", nil)
+		return
+	}
+	nonce, verifier := flow[1], flow[2]
+
+	if providerErr := q.Get("error"); providerErr != "" {
+		h.fail(w, r, http.StatusUnauthorized, "provider denied the login", fmt.Errorf("%s: %s", providerErr, q.Get("error_description")))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), flowTimeout)
+	defer cancel()
+
+	idToken, err := h.provider.Exchange(ctx, q.Get("code"), verifier)
+	if err != nil {
+		h.fail(w, r, http.StatusBadGateway, "failed to exchange authorization code", err)
+		return
+	}
+
+	// the nonce ties the ID token to this browser, the API checks everything else again
+	claims, err := h.provider.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		h.fail(w, r, http.StatusUnauthorized, "invalid ID token", err)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		h.fail(w, r, http.StatusUnauthorized, "nonce mismatch", nil)
+		return
+	}
+
+	resp, err := h.client.LoginWithOIDC(ctx, &pb.LoginWithOIDCRequest{IdToken: idToken, Nonce: nonce})
+	if err != nil {
+		h.fail(w, r, runtime.HTTPStatusFromCode(status.Code(err)), "failed to login", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if h.conf.CompleteURL != "" {
+		// the fragment is not sent to servers, so the tokens stay in the browser
+		fragment := url.Values{}
+		fragment.Set("user_id", resp.GetUserId())
+		if resp.GetTwoFactorRequired() {
+			fragment.Set("two_factor_required", "true")
+			fragment.Set("challenge_token", resp.GetChallengeToken())
+		} else {
+			fragment.Set("token", resp.GetToken())
+			fragment.Set("refresh_token", resp.GetRefreshToken())
+			fragment.Set("expires_in", strconv.FormatInt(resp.GetExpiresIn(), 10))
+		}
+
+		http.Redirect(w, r, h.conf.CompleteURL+"#"+fragment.Encode(), http.StatusSeeOther)
+		return
+	}
+
+	b, err := protojson.Marshal(resp)
+	if err != nil {
+		h.fail(w, r, http.StatusInternalServerError, "failed to encode response", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func (h *Handler) setFlowCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     flowCookie,
+		Value:    value,
+		Path:     PathPrefix,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.conf.RedirectURL, "https://"),
+		// the callback is a top-level navigation from the provider, which lax cookies are sent with
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, code int, msg string, err error) {
+	h.logger.Warn("OpenID Connect login failed", zap.String("path", r.URL.Path), zap.String("reason", msg), zap.Error(err))
+	http.Error(w, msg, code)
+}
@@ -0,0 +1,240 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/authkit/oidctest"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+)
+
+const testClientID = "blog"
+
+// grant issues code for the login redirected to authURL, with the ID token of claims.
+func grant(t *testing.T, iss *oidctest.Issuer, authURL, code string, claims jwt.MapClaims) {
+	t.Helper()
+
+	iss.Grant(code, query(t, authURL).Get("code_challenge"), iss.Sign(t, claims))
+}
+
+// validClaims returns the claims of a valid ID token for the login redirected to authURL.
+func validClaims(t *testing.T, iss *oidctest.Issuer, authURL string) jwt.MapClaims {
+	t.Helper()
+
+	return iss.Claims("alice", query(t, authURL).Get("nonce"))
+}
+
+func query(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u.Query()
+}
+
+type fakeSessionClient struct {
+	pb.SessionClient
+	requests []*pb.LoginWithOIDCRequest
+}
+
+func (c *fakeSessionClient) LoginWithOIDC(ctx context.Context, in *pb.LoginWithOIDCRequest, opts ...grpc.CallOption) (*pb.LoginResponse, error) {
+	c.requests = append(c.requests, in)
+
+	return &pb.LoginResponse{UserId: "user", Token: "token", RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+func newTestHandler(iss *oidctest.Issuer) (*Handler, *fakeSessionClient) {
+	conf := &authkit.OIDCConfig{
+		IssuerURL:   iss.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://blog.example/oidc/callback",
+		Scopes:      []string{"openid"},
+	}
+	client := &fakeSessionClient{}
+
+	return NewHandler(authkit.NewOIDCProvider(conf), client, conf, logkit.NewLogger(&logkit.LoggerConfig{})), client
+}
+
+// startLogin runs /oidc/login and returns the authorization URL and the flow cookie.
+func startLogin(t *testing.T, h *Handler) (string, *http.Cookie) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login returned %d: %s", w.Code, w.Body)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != flowCookie {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+
+	return w.Header().Get("Location"), cookies[0]
+}
+
+func callback(h *Handler, cookie *http.Cookie, state, code string) *httptest.ResponseRecorder {
+	q := url.Values{}
+	q.Set("state", state)
+	q.Set("code", code)
+
+	r := httptest.NewRequest(http.MethodGet, "/oidc/callback?"+q.Encode(), nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestCallback(t *testing.T) {
+	iss := oidctest.NewIssuer(t, testClientID)
+	h, client := newTestHandler(iss)
+
+	authURL, cookie := startLogin(t, h)
+	grant(t, iss, authURL, "code", validClaims(t, iss, authURL))
+
+	w := callback(h, cookie, query(t, authURL).Get("state"), "code")
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback returned %d: %s", w.Code, w.Body)
+	}
+
+	var resp struct {
+		UserID string `json:"userId"`
+		Token  string `json:"token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.UserID != "user" || resp.Token != "token" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	if len(client.requests) != 1 || client.requests[0].GetNonce() != query(t, authURL).Get("nonce") {
+		t.Fatalf("API was not called with the nonce of the login: %v", client.requests)
+	}
+
+	// the flow cookie is cleared so the callback can not be replayed
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("flow cookie was not cleared: %v", cookies)
+	}
+}
+
+func TestCallbackRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		// callback runs the callback of the login redirected to authURL
+		callback func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder
+		code     int
+	}{
+		{
+			name: "no login in progress",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				grant(t, iss, authURL, "code", validClaims(t, iss, authURL))
+				return callback(h, nil, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "bad state",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				grant(t, iss, authURL, "code", validClaims(t, iss, authURL))
+				return callback(h, cookie, "other-state", "code")
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "bad nonce",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				claims := validClaims(t, iss, authURL)
+				claims["nonce"] = "other-nonce"
+				grant(t, iss, authURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "no nonce",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				claims := validClaims(t, iss, authURL)
+				delete(claims, "nonce")
+				grant(t, iss, authURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong audience",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				claims := validClaims(t, iss, authURL)
+				claims["aud"] = "other"
+				grant(t, iss, authURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong authorized party",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				claims := validClaims(t, iss, authURL)
+				claims["aud"] = []string{testClientID, "other"}
+				claims["azp"] = "other"
+				grant(t, iss, authURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "expired token",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				claims := validClaims(t, iss, authURL)
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				grant(t, iss, authURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "code of another login",
+			callback: func(t *testing.T, iss *oidctest.Issuer, h *Handler, authURL string, cookie *http.Cookie) *httptest.ResponseRecorder {
+				otherURL, _ := startLogin(t, h)
+				claims := validClaims(t, iss, otherURL)
+				claims["nonce"] = validClaims(t, iss, authURL)["nonce"]
+				// the code challenge of the other login does not match the code verifier of this one
+				grant(t, iss, otherURL, "code", claims)
+				return callback(h, cookie, query(t, authURL).Get("state"), "code")
+			},
+			code: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iss := oidctest.NewIssuer(t, testClientID)
+			h, client := newTestHandler(iss)
+
+			authURL, cookie := startLogin(t, h)
+			w := tt.callback(t, iss, h, authURL, cookie)
+			if w.Code != tt.code {
+				t.Fatalf("callback returned %d, want %d: %s", w.Code, tt.code, w.Body)
+			}
+			if len(client.requests) != 0 {
+				t.Fatal("API was called for a rejected login")
+			}
+		})
+	}
+}
@@ -29,7 +29,7 @@ var file_modules_api_proto_post_rpc_proto_rawDesc = []byte{
 	0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x24, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x2f, 0x61, 0x70,
 	0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x9e, 0x06, 0x0a, 0x04, 0x50,
+	0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xa8, 0x0d, 0x0a, 0x04, 0x50,
 	0x6f, 0x73, 0x74, 0x12, 0x52, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x12,
 	0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52,
@@ -45,81 +45,170 @@ var file_modules_api_proto_post_rpc_proto_rawDesc = []byte{
 	0x6f, 0x73, 0x74, 0x42, 0x79, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x74,
 	0x42, 0x79, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x12, 0x19, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73,
-	0x2f, 0x62, 0x79, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x2f, 0x7b, 0x75, 0x73, 0x65, 0x72, 0x5f,
-	0x69, 0x64, 0x7d, 0x62, 0x01, 0x2a, 0x12, 0x51, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x50, 0x6f, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62,
-	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x22, 0x06, 0x2f, 0x70, 0x6f,
-	0x73, 0x74, 0x73, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x78, 0x0a, 0x11, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x1c,
-	0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x43, 0x6f,
-	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70,
-	0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x20, 0x1a, 0x18, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x6e, 0x74, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x3a, 0x01, 0x2a,
-	0x62, 0x01, 0x2a, 0x12, 0x6d, 0x0a, 0x0f, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73,
-	0x74, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f,
-	0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x1a, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f,
-	0x6c, 0x69, 0x6b, 0x65, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x62,
+	0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x62, 0x01, 0x2a, 0x12, 0x19, 0x2f, 0x70, 0x6f,
+	0x73, 0x74, 0x73, 0x2f, 0x62, 0x79, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x2f, 0x7b, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x44, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65,
+	0x64, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x65, 0x65, 0x64, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x65,
+	0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x10, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x0a, 0x12, 0x05, 0x2f, 0x66, 0x65, 0x65, 0x64, 0x62, 0x01, 0x2a, 0x12, 0x51, 0x0a, 0x0a,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x0e, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x22, 0x06, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x12,
+	0x78, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x50, 0x6f, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f,
+	0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x1a, 0x18, 0x2f, 0x70, 0x6f, 0x73, 0x74,
+	0x73, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f,
+	0x69, 0x64, 0x7d, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x6d, 0x0a, 0x0f, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x70,
+	0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x62, 0x01, 0x2a,
+	0x1a, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x2f, 0x7b,
+	0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x58, 0x0a, 0x08, 0x4c, 0x69, 0x6b, 0x65,
+	0x50, 0x6f, 0x73, 0x74, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x6b, 0x65, 0x50, 0x6f,
+	0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x6b, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x22, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f,
+	0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x62,
+	0x01, 0x2a, 0x12, 0x5e, 0x0a, 0x0a, 0x55, 0x6e, 0x6c, 0x69, 0x6b, 0x65, 0x50, 0x6f, 0x73, 0x74,
+	0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x69, 0x6b, 0x65, 0x50, 0x6f, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x6c,
+	0x69, 0x6b, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x2a, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f,
+	0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x62,
+	0x01, 0x2a, 0x12, 0x5e, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x73,
+	0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x4c, 0x69, 0x6b, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f,
+	0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x6c, 0x69, 0x6b, 0x65, 0x73, 0x62,
 	0x01, 0x2a, 0x12, 0x6d, 0x0a, 0x0f, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74,
 	0x56, 0x69, 0x65, 0x77, 0x73, 0x12, 0x1a, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
 	0x65, 0x50, 0x6f, 0x73, 0x74, 0x56, 0x69, 0x65, 0x77, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73,
 	0x74, 0x56, 0x69, 0x65, 0x77, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x1a, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x76,
-	0x69, 0x65, 0x77, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x62, 0x01,
-	0x2a, 0x12, 0x58, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12,
-	0x15, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x2a, 0x10, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x7b,
-	0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x62, 0x01, 0x2a, 0x42, 0x05, 0x5a, 0x03, 0x2f,
-	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x62, 0x01, 0x2a, 0x1a, 0x16, 0x2f, 0x70, 0x6f, 0x73, 0x74,
+	0x73, 0x2f, 0x76, 0x69, 0x65, 0x77, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64,
+	0x7d, 0x12, 0x77, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f,
+	0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1f, 0x12, 0x1a, 0x2f, 0x70, 0x6f,
+	0x73, 0x74, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x62, 0x01, 0x2a, 0x12, 0x86, 0x01, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x62, 0x2e,
+	0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x34, 0x12,
+	0x28, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64,
+	0x7d, 0x2f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x7d, 0x62, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x8f, 0x01, 0x0a, 0x11, 0x44, 0x69, 0x66, 0x66, 0x50, 0x6f, 0x73, 0x74,
+	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x44,
+	0x69, 0x66, 0x66, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x69, 0x66,
+	0x66, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x37, 0x12, 0x32,
+	0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d,
+	0x2f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x7b, 0x66, 0x72, 0x6f, 0x6d,
+	0x5f, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x64, 0x69,
+	0x66, 0x66, 0x62, 0x01, 0x2a, 0x12, 0x93, 0x01, 0x0a, 0x13, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x2e,
+	0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
+	0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3b,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x35, 0x62, 0x01, 0x2a, 0x22, 0x30, 0x2f, 0x70, 0x6f, 0x73, 0x74,
+	0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x7b, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f,
+	0x69, 0x64, 0x7d, 0x2f, 0x72, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x58, 0x0a, 0x0a, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15,
+	0x2a, 0x10, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x2f, 0x7b, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x69,
+	0x64, 0x7d, 0x62, 0x01, 0x2a, 0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_modules_api_proto_post_rpc_proto_goTypes = []interface{}{
-	(*GetPostRequest)(nil),            // 0: pb.GetPostRequest
-	(*ListPostRequest)(nil),           // 1: pb.ListPostRequest
-	(*ListPostByUserIDRequest)(nil),   // 2: pb.ListPostByUserIDRequest
-	(*CreatePostRequest)(nil),         // 3: pb.CreatePostRequest
-	(*UpdatePostContentRequest)(nil),  // 4: pb.UpdatePostContentRequest
-	(*UpdatePostLikesRequest)(nil),    // 5: pb.UpdatePostLikesRequest
-	(*UpdatePostViewsRequest)(nil),    // 6: pb.UpdatePostViewsRequest
-	(*DeletePostRequest)(nil),         // 7: pb.DeletePostRequest
-	(*GetPostResponse)(nil),           // 8: pb.GetPostResponse
-	(*ListPostResponse)(nil),          // 9: pb.ListPostResponse
-	(*ListPostByUserIDResponse)(nil),  // 10: pb.ListPostByUserIDResponse
-	(*CreatePostResponse)(nil),        // 11: pb.CreatePostResponse
-	(*UpdatePostContentResponse)(nil), // 12: pb.UpdatePostContentResponse
-	(*UpdatePostLikesResponse)(nil),   // 13: pb.UpdatePostLikesResponse
-	(*UpdatePostViewsResponse)(nil),   // 14: pb.UpdatePostViewsResponse
-	(*DeletePostResponse)(nil),        // 15: pb.DeletePostResponse
+	(*GetPostRequest)(nil),              // 0: pb.GetPostRequest
+	(*ListPostRequest)(nil),             // 1: pb.ListPostRequest
+	(*ListPostByUserIDRequest)(nil),     // 2: pb.ListPostByUserIDRequest
+	(*GetFeedRequest)(nil),              // 3: pb.GetFeedRequest
+	(*CreatePostRequest)(nil),           // 4: pb.CreatePostRequest
+	(*UpdatePostContentRequest)(nil),    // 5: pb.UpdatePostContentRequest
+	(*UpdatePostLikesRequest)(nil),      // 6: pb.UpdatePostLikesRequest
+	(*LikePostRequest)(nil),             // 7: pb.LikePostRequest
+	(*UnlikePostRequest)(nil),           // 8: pb.UnlikePostRequest
+	(*ListLikersRequest)(nil),           // 9: pb.ListLikersRequest
+	(*UpdatePostViewsRequest)(nil),      // 10: pb.UpdatePostViewsRequest
+	(*ListPostRevisionsRequest)(nil),    // 11: pb.ListPostRevisionsRequest
+	(*GetPostRevisionRequest)(nil),      // 12: pb.GetPostRevisionRequest
+	(*DiffPostRevisionsRequest)(nil),    // 13: pb.DiffPostRevisionsRequest
+	(*RestorePostRevisionRequest)(nil),  // 14: pb.RestorePostRevisionRequest
+	(*DeletePostRequest)(nil),           // 15: pb.DeletePostRequest
+	(*GetPostResponse)(nil),             // 16: pb.GetPostResponse
+	(*ListPostResponse)(nil),            // 17: pb.ListPostResponse
+	(*ListPostByUserIDResponse)(nil),    // 18: pb.ListPostByUserIDResponse
+	(*GetFeedResponse)(nil),             // 19: pb.GetFeedResponse
+	(*CreatePostResponse)(nil),          // 20: pb.CreatePostResponse
+	(*UpdatePostContentResponse)(nil),   // 21: pb.UpdatePostContentResponse
+	(*UpdatePostLikesResponse)(nil),     // 22: pb.UpdatePostLikesResponse
+	(*LikePostResponse)(nil),            // 23: pb.LikePostResponse
+	(*UnlikePostResponse)(nil),          // 24: pb.UnlikePostResponse
+	(*ListLikersResponse)(nil),          // 25: pb.ListLikersResponse
+	(*UpdatePostViewsResponse)(nil),     // 26: pb.UpdatePostViewsResponse
+	(*ListPostRevisionsResponse)(nil),   // 27: pb.ListPostRevisionsResponse
+	(*GetPostRevisionResponse)(nil),     // 28: pb.GetPostRevisionResponse
+	(*DiffPostRevisionsResponse)(nil),   // 29: pb.DiffPostRevisionsResponse
+	(*RestorePostRevisionResponse)(nil), // 30: pb.RestorePostRevisionResponse
+	(*DeletePostResponse)(nil),          // 31: pb.DeletePostResponse
 }
 var file_modules_api_proto_post_rpc_proto_depIdxs = []int32{
 	0,  // 0: pb.Post.GetPost:input_type -> pb.GetPostRequest
 	1,  // 1: pb.Post.ListPost:input_type -> pb.ListPostRequest
 	2,  // 2: pb.Post.ListPostByUserID:input_type -> pb.ListPostByUserIDRequest
-	3,  // 3: pb.Post.CreatePost:input_type -> pb.CreatePostRequest
-	4,  // 4: pb.Post.UpdatePostContent:input_type -> pb.UpdatePostContentRequest
-	5,  // 5: pb.Post.UpdatePostLikes:input_type -> pb.UpdatePostLikesRequest
-	6,  // 6: pb.Post.UpdatePostViews:input_type -> pb.UpdatePostViewsRequest
-	7,  // 7: pb.Post.DeletePost:input_type -> pb.DeletePostRequest
-	8,  // 8: pb.Post.GetPost:output_type -> pb.GetPostResponse
-	9,  // 9: pb.Post.ListPost:output_type -> pb.ListPostResponse
-	10, // 10: pb.Post.ListPostByUserID:output_type -> pb.ListPostByUserIDResponse
-	11, // 11: pb.Post.CreatePost:output_type -> pb.CreatePostResponse
-	12, // 12: pb.Post.UpdatePostContent:output_type -> pb.UpdatePostContentResponse
-	13, // 13: pb.Post.UpdatePostLikes:output_type -> pb.UpdatePostLikesResponse
-	14, // 14: pb.Post.UpdatePostViews:output_type -> pb.UpdatePostViewsResponse
-	15, // 15: pb.Post.DeletePost:output_type -> pb.DeletePostResponse
-	8,  // [8:16] is the sub-list for method output_type
-	0,  // [0:8] is the sub-list for method input_type
+	3,  // 3: pb.Post.GetFeed:input_type -> pb.GetFeedRequest
+	4,  // 4: pb.Post.CreatePost:input_type -> pb.CreatePostRequest
+	5,  // 5: pb.Post.UpdatePostContent:input_type -> pb.UpdatePostContentRequest
+	6,  // 6: pb.Post.UpdatePostLikes:input_type -> pb.UpdatePostLikesRequest
+	7,  // 7: pb.Post.LikePost:input_type -> pb.LikePostRequest
+	8,  // 8: pb.Post.UnlikePost:input_type -> pb.UnlikePostRequest
+	9,  // 9: pb.Post.ListLikers:input_type -> pb.ListLikersRequest
+	10, // 10: pb.Post.UpdatePostViews:input_type -> pb.UpdatePostViewsRequest
+	11, // 11: pb.Post.ListPostRevisions:input_type -> pb.ListPostRevisionsRequest
+	12, // 12: pb.Post.GetPostRevision:input_type -> pb.GetPostRevisionRequest
+	13, // 13: pb.Post.DiffPostRevisions:input_type -> pb.DiffPostRevisionsRequest
+	14, // 14: pb.Post.RestorePostRevision:input_type -> pb.RestorePostRevisionRequest
+	15, // 15: pb.Post.DeletePost:input_type -> pb.DeletePostRequest
+	16, // 16: pb.Post.GetPost:output_type -> pb.GetPostResponse
+	17, // 17: pb.Post.ListPost:output_type -> pb.ListPostResponse
+	18, // 18: pb.Post.ListPostByUserID:output_type -> pb.ListPostByUserIDResponse
+	19, // 19: pb.Post.GetFeed:output_type -> pb.GetFeedResponse
+	20, // 20: pb.Post.CreatePost:output_type -> pb.CreatePostResponse
+	21, // 21: pb.Post.UpdatePostContent:output_type -> pb.UpdatePostContentResponse
+	22, // 22: pb.Post.UpdatePostLikes:output_type -> pb.UpdatePostLikesResponse
+	23, // 23: pb.Post.LikePost:output_type -> pb.LikePostResponse
+	24, // 24: pb.Post.UnlikePost:output_type -> pb.UnlikePostResponse
+	25, // 25: pb.Post.ListLikers:output_type -> pb.ListLikersResponse
+	26, // 26: pb.Post.UpdatePostViews:output_type -> pb.UpdatePostViewsResponse
+	27, // 27: pb.Post.ListPostRevisions:output_type -> pb.ListPostRevisionsResponse
+	28, // 28: pb.Post.GetPostRevision:output_type -> pb.GetPostRevisionResponse
+	29, // 29: pb.Post.DiffPostRevisions:output_type -> pb.DiffPostRevisionsResponse
+	30, // 30: pb.Post.RestorePostRevision:output_type -> pb.RestorePostRevisionResponse
+	31, // 31: pb.Post.DeletePost:output_type -> pb.DeletePostResponse
+	16, // [16:32] is the sub-list for method output_type
+	0,  // [0:16] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -29,7 +29,7 @@ var file_modules_api_proto_user_rpc_proto_rawDesc = []byte{
 	0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x24, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x2f, 0x61, 0x70,
 	0x69, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x97, 0x03, 0x0a, 0x04, 0x55,
+	0x73, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xd3, 0x10, 0x0a, 0x04, 0x55,
 	0x73, 0x65, 0x72, 0x12, 0x51, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65,
 	0x72, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65,
 	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x72,
@@ -49,44 +49,215 @@ var file_modules_api_proto_user_rpc_proto_rawDesc = []byte{
 	0x15, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61,
 	0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x14,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x1a, 0x06, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x3a, 0x01,
-	0x2a, 0x62, 0x01, 0x2a, 0x12, 0x4e, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x1a, 0x06, 0x2f, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x12, 0x4e, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
 	0x65, 0x72, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
 	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x44,
 	0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
 	0x65, 0x22, 0x11, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0b, 0x2a, 0x06, 0x2f, 0x75, 0x73, 0x65, 0x72,
-	0x73, 0x62, 0x01, 0x2a, 0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x73, 0x62, 0x01, 0x2a, 0x12, 0x53, 0x0a, 0x06, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x11,
+	0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x17, 0x2f,
+	0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x7d, 0x2f,
+	0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x62, 0x01, 0x2a, 0x12, 0x59, 0x0a, 0x08, 0x55, 0x6e, 0x66,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x6e, 0x66, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e,
+	0x55, 0x6e, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x2a, 0x17, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73,
+	0x2f, 0x7b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x77, 0x62, 0x01, 0x2a, 0x12, 0x6b, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x77, 0x65, 0x72, 0x73, 0x12, 0x18, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x19, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65,
+	0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x1f, 0x12, 0x1a, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x75, 0x73, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x73, 0x62, 0x01,
+	0x2a, 0x12, 0x6b, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x69,
+	0x6e, 0x67, 0x12, 0x18, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x77, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70,
+	0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1f, 0x12,
+	0x1a, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x7b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64,
+	0x7d, 0x2f, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x69, 0x6e, 0x67, 0x62, 0x01, 0x2a, 0x12, 0x63,
+	0x0a, 0x0b, 0x53, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x16, 0x2e,
+	0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x55, 0x73,
+	0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x1a, 0x15, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x7b,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x72, 0x6f, 0x6c, 0x65, 0x3a, 0x01, 0x2a,
+	0x62, 0x01, 0x2a, 0x12, 0x66, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73, 0x73,
+	0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x17, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x1a, 0x0f, 0x2f, 0x75, 0x73, 0x65,
+	0x72, 0x73, 0x2f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x7e, 0x0a, 0x14, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65,
+	0x73, 0x65, 0x74, 0x12, 0x1f, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x3a, 0x01,
+	0x2a, 0x62, 0x01, 0x2a, 0x22, 0x15, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x70, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x86, 0x01, 0x0a, 0x14,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52,
+	0x65, 0x73, 0x65, 0x74, 0x12, 0x1f, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72,
+	0x6d, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x72, 0x6d, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x62,
+	0x01, 0x2a, 0x22, 0x1d, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x74, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72,
+	0x6d, 0x3a, 0x01, 0x2a, 0x12, 0x56, 0x0a, 0x0a, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x54, 0x4f,
+	0x54, 0x50, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x54, 0x4f,
+	0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x45,
+	0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13, 0x22, 0x0b, 0x2f, 0x75, 0x73, 0x65, 0x72,
+	0x73, 0x2f, 0x74, 0x6f, 0x74, 0x70, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x65, 0x0a, 0x0c,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x17, 0x2e, 0x70,
+	0x62, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x22, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x22, 0x14, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f,
+	0x74, 0x6f, 0x74, 0x70, 0x2f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x3a, 0x01, 0x2a,
+	0x62, 0x01, 0x2a, 0x12, 0x61, 0x0a, 0x0b, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x4f,
+	0x54, 0x50, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x54,
+	0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x62, 0x2e,
+	0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x22, 0x13, 0x2f, 0x75, 0x73,
+	0x65, 0x72, 0x73, 0x2f, 0x74, 0x6f, 0x74, 0x70, 0x2f, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x6d, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x2e, 0x70, 0x62,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15,
+	0x22, 0x0d, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x3a,
+	0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x67, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x1b, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x18, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x12, 0x12, 0x0d, 0x2f, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x62, 0x01, 0x2a, 0x12, 0x75,
+	0x0a, 0x11, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x41,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x2a, 0x18, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73,
+	0x2f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x2f, 0x7b, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x69,
+	0x64, 0x7d, 0x62, 0x01, 0x2a, 0x12, 0x85, 0x01, 0x0a, 0x15, 0x53, 0x65, 0x6e, 0x64, 0x45, 0x6d,
+	0x61, 0x69, 0x6c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x20, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x21, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x45, 0x6d, 0x61, 0x69, 0x6c,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x27, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x21, 0x22, 0x19, 0x2f, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x2f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x2f, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x12, 0x61, 0x0a,
+	0x0b, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x16, 0x2e, 0x70,
+	0x62, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x45, 0x6d, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x3a, 0x01, 0x2a, 0x62, 0x01, 0x2a, 0x22, 0x13, 0x2f, 0x75, 0x73,
+	0x65, 0x72, 0x73, 0x2f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x2f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x42, 0x05, 0x5a, 0x03, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_modules_api_proto_user_rpc_proto_goTypes = []interface{}{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*GetUserRequest)(nil),     // 1: pb.GetUserRequest
-	(*ListUserRequest)(nil),    // 2: pb.ListUserRequest
-	(*UpdateUserRequest)(nil),  // 3: pb.UpdateUserRequest
-	(*DeleteUserRequest)(nil),  // 4: pb.DeleteUserRequest
-	(*CreateUserResponse)(nil), // 5: pb.CreateUserResponse
-	(*GetUserResponse)(nil),    // 6: pb.GetUserResponse
-	(*ListUserResponse)(nil),   // 7: pb.ListUserResponse
-	(*UpdateUserResponse)(nil), // 8: pb.UpdateUserResponse
-	(*DeleteUserResponse)(nil), // 9: pb.DeleteUserResponse
+	(*CreateUserRequest)(nil),             // 0: pb.CreateUserRequest
+	(*GetUserRequest)(nil),                // 1: pb.GetUserRequest
+	(*ListUserRequest)(nil),               // 2: pb.ListUserRequest
+	(*UpdateUserRequest)(nil),             // 3: pb.UpdateUserRequest
+	(*DeleteUserRequest)(nil),             // 4: pb.DeleteUserRequest
+	(*FollowRequest)(nil),                 // 5: pb.FollowRequest
+	(*UnfollowRequest)(nil),               // 6: pb.UnfollowRequest
+	(*ListFollowersRequest)(nil),          // 7: pb.ListFollowersRequest
+	(*ListFollowingRequest)(nil),          // 8: pb.ListFollowingRequest
+	(*SetUserRoleRequest)(nil),            // 9: pb.SetUserRoleRequest
+	(*ChangePasswordRequest)(nil),         // 10: pb.ChangePasswordRequest
+	(*RequestPasswordResetRequest)(nil),   // 11: pb.RequestPasswordResetRequest
+	(*ConfirmPasswordResetRequest)(nil),   // 12: pb.ConfirmPasswordResetRequest
+	(*EnrollTOTPRequest)(nil),             // 13: pb.EnrollTOTPRequest
+	(*ActivateTOTPRequest)(nil),           // 14: pb.ActivateTOTPRequest
+	(*DisableTOTPRequest)(nil),            // 15: pb.DisableTOTPRequest
+	(*CreateAccessTokenRequest)(nil),      // 16: pb.CreateAccessTokenRequest
+	(*ListAccessTokensRequest)(nil),       // 17: pb.ListAccessTokensRequest
+	(*RevokeAccessTokenRequest)(nil),      // 18: pb.RevokeAccessTokenRequest
+	(*SendEmailVerificationRequest)(nil),  // 19: pb.SendEmailVerificationRequest
+	(*VerifyEmailRequest)(nil),            // 20: pb.VerifyEmailRequest
+	(*CreateUserResponse)(nil),            // 21: pb.CreateUserResponse
+	(*GetUserResponse)(nil),               // 22: pb.GetUserResponse
+	(*ListUserResponse)(nil),              // 23: pb.ListUserResponse
+	(*UpdateUserResponse)(nil),            // 24: pb.UpdateUserResponse
+	(*DeleteUserResponse)(nil),            // 25: pb.DeleteUserResponse
+	(*FollowResponse)(nil),                // 26: pb.FollowResponse
+	(*UnfollowResponse)(nil),              // 27: pb.UnfollowResponse
+	(*ListFollowersResponse)(nil),         // 28: pb.ListFollowersResponse
+	(*ListFollowingResponse)(nil),         // 29: pb.ListFollowingResponse
+	(*SetUserRoleResponse)(nil),           // 30: pb.SetUserRoleResponse
+	(*ChangePasswordResponse)(nil),        // 31: pb.ChangePasswordResponse
+	(*RequestPasswordResetResponse)(nil),  // 32: pb.RequestPasswordResetResponse
+	(*ConfirmPasswordResetResponse)(nil),  // 33: pb.ConfirmPasswordResetResponse
+	(*EnrollTOTPResponse)(nil),            // 34: pb.EnrollTOTPResponse
+	(*ActivateTOTPResponse)(nil),          // 35: pb.ActivateTOTPResponse
+	(*DisableTOTPResponse)(nil),           // 36: pb.DisableTOTPResponse
+	(*CreateAccessTokenResponse)(nil),     // 37: pb.CreateAccessTokenResponse
+	(*ListAccessTokensResponse)(nil),      // 38: pb.ListAccessTokensResponse
+	(*RevokeAccessTokenResponse)(nil),     // 39: pb.RevokeAccessTokenResponse
+	(*SendEmailVerificationResponse)(nil), // 40: pb.SendEmailVerificationResponse
+	(*VerifyEmailResponse)(nil),           // 41: pb.VerifyEmailResponse
 }
 var file_modules_api_proto_user_rpc_proto_depIdxs = []int32{
-	0, // 0: pb.User.CreateUser:input_type -> pb.CreateUserRequest
-	1, // 1: pb.User.GetUser:input_type -> pb.GetUserRequest
-	2, // 2: pb.User.ListUser:input_type -> pb.ListUserRequest
-	3, // 3: pb.User.UpdateUser:input_type -> pb.UpdateUserRequest
-	4, // 4: pb.User.DeleteUser:input_type -> pb.DeleteUserRequest
-	5, // 5: pb.User.CreateUser:output_type -> pb.CreateUserResponse
-	6, // 6: pb.User.GetUser:output_type -> pb.GetUserResponse
-	7, // 7: pb.User.ListUser:output_type -> pb.ListUserResponse
-	8, // 8: pb.User.UpdateUser:output_type -> pb.UpdateUserResponse
-	9, // 9: pb.User.DeleteUser:output_type -> pb.DeleteUserResponse
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.User.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.User.GetUser:input_type -> pb.GetUserRequest
+	2,  // 2: pb.User.ListUser:input_type -> pb.ListUserRequest
+	3,  // 3: pb.User.UpdateUser:input_type -> pb.UpdateUserRequest
+	4,  // 4: pb.User.DeleteUser:input_type -> pb.DeleteUserRequest
+	5,  // 5: pb.User.Follow:input_type -> pb.FollowRequest
+	6,  // 6: pb.User.Unfollow:input_type -> pb.UnfollowRequest
+	7,  // 7: pb.User.ListFollowers:input_type -> pb.ListFollowersRequest
+	8,  // 8: pb.User.ListFollowing:input_type -> pb.ListFollowingRequest
+	9,  // 9: pb.User.SetUserRole:input_type -> pb.SetUserRoleRequest
+	10, // 10: pb.User.ChangePassword:input_type -> pb.ChangePasswordRequest
+	11, // 11: pb.User.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	12, // 12: pb.User.ConfirmPasswordReset:input_type -> pb.ConfirmPasswordResetRequest
+	13, // 13: pb.User.EnrollTOTP:input_type -> pb.EnrollTOTPRequest
+	14, // 14: pb.User.ActivateTOTP:input_type -> pb.ActivateTOTPRequest
+	15, // 15: pb.User.DisableTOTP:input_type -> pb.DisableTOTPRequest
+	16, // 16: pb.User.CreateAccessToken:input_type -> pb.CreateAccessTokenRequest
+	17, // 17: pb.User.ListAccessTokens:input_type -> pb.ListAccessTokensRequest
+	18, // 18: pb.User.RevokeAccessToken:input_type -> pb.RevokeAccessTokenRequest
+	19, // 19: pb.User.SendEmailVerification:input_type -> pb.SendEmailVerificationRequest
+	20, // 20: pb.User.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	21, // 21: pb.User.CreateUser:output_type -> pb.CreateUserResponse
+	22, // 22: pb.User.GetUser:output_type -> pb.GetUserResponse
+	23, // 23: pb.User.ListUser:output_type -> pb.ListUserResponse
+	24, // 24: pb.User.UpdateUser:output_type -> pb.UpdateUserResponse
+	25, // 25: pb.User.DeleteUser:output_type -> pb.DeleteUserResponse
+	26, // 26: pb.User.Follow:output_type -> pb.FollowResponse
+	27, // 27: pb.User.Unfollow:output_type -> pb.UnfollowResponse
+	28, // 28: pb.User.ListFollowers:output_type -> pb.ListFollowersResponse
+	29, // 29: pb.User.ListFollowing:output_type -> pb.ListFollowingResponse
+	30, // 30: pb.User.SetUserRole:output_type -> pb.SetUserRoleResponse
+	31, // 31: pb.User.ChangePassword:output_type -> pb.ChangePasswordResponse
+	32, // 32: pb.User.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	33, // 33: pb.User.ConfirmPasswordReset:output_type -> pb.ConfirmPasswordResetResponse
+	34, // 34: pb.User.EnrollTOTP:output_type -> pb.EnrollTOTPResponse
+	35, // 35: pb.User.ActivateTOTP:output_type -> pb.ActivateTOTPResponse
+	36, // 36: pb.User.DisableTOTP:output_type -> pb.DisableTOTPResponse
+	37, // 37: pb.User.CreateAccessToken:output_type -> pb.CreateAccessTokenResponse
+	38, // 38: pb.User.ListAccessTokens:output_type -> pb.ListAccessTokensResponse
+	39, // 39: pb.User.RevokeAccessToken:output_type -> pb.RevokeAccessTokenResponse
+	40, // 40: pb.User.SendEmailVerification:output_type -> pb.SendEmailVerificationResponse
+	41, // 41: pb.User.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	21, // [21:42] is the sub-list for method output_type
+	0,  // [0:21] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_modules_api_proto_user_rpc_proto_init() }
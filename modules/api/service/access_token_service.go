@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxAccessTokenNameLength = 64
+
+// CreateAccessToken creates a personal access token of the caller, the token itself is only returned here.
+func (s *Service) CreateAccessToken(ctx context.Context, req *pb.CreateAccessTokenRequest) (*pb.CreateAccessTokenResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n := utf8.RuneCountInString(req.GetName()); n == 0 || n > maxAccessTokenNameLength {
+		return nil, ErrInvalidTokenName
+	}
+
+	if len(req.GetScopes()) == 0 {
+		return nil, ErrInvalidTokenScope
+	}
+	scopes := make([]string, 0, len(req.GetScopes()))
+	seen := make(map[string]bool, len(req.GetScopes()))
+	for _, scope := range req.GetScopes() {
+		if !authkit.ValidScope(scope) {
+			return nil, ErrInvalidTokenScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now()
+
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = req.GetExpiresAt().AsTime()
+		if !expiresAt.After(now) {
+			return nil, ErrInvalidTokenExpiry
+		}
+	}
+
+	token, tokenHash, err := authkit.NewAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken := &authkit.AccessToken{
+		UserID:    userID,
+		Name:      req.GetName(),
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		Role:      getRoleFromMetadata(ctx),
+		ExpiresAT: expiresAt,
+		CreatedAT: now,
+	}
+	if _, err := s.accessTokens.Create(ctx, accessToken); err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateAccessTokenResponse{
+		Token: token,
+		Info:  accessTokenToProto(accessToken),
+	}, nil
+}
+
+func (s *Service) ListAccessTokens(ctx context.Context, req *pb.ListAccessTokensRequest) (*pb.ListAccessTokensResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTokens, err := s.accessTokens.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*pb.AccessTokenInfo, 0, len(accessTokens))
+	for _, accessToken := range accessTokens {
+		tokens = append(tokens, accessTokenToProto(accessToken))
+	}
+
+	return &pb.ListAccessTokensResponse{Tokens: tokens}, nil
+}
+
+func (s *Service) RevokeAccessToken(ctx context.Context, req *pb.RevokeAccessTokenRequest) (*pb.RevokeAccessTokenResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := primitive.ObjectIDFromHex(req.GetTokenId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	if err := s.accessTokens.Delete(ctx, tokenID, userID); err != nil {
+		if errors.Is(err, authkit.ErrAccessTokenNotFound) {
+			return nil, ErrAccessTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return &pb.RevokeAccessTokenResponse{}, nil
+}
+
+func accessTokenToProto(t *authkit.AccessToken) *pb.AccessTokenInfo {
+	info := &pb.AccessTokenInfo{
+		TokenId:   t.ID.Hex(),
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		CreatedAt: timestamppb.New(t.CreatedAT),
+	}
+	if !t.ExpiresAT.IsZero() {
+		info.ExpiresAt = timestamppb.New(t.ExpiresAT)
+	}
+	if !t.LastUsedAT.IsZero() {
+		info.LastUsedAt = timestamppb.New(t.LastUsedAT)
+	}
+
+	return info
+}
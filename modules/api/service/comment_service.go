@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxCommentContentLength = 10000
+
+func (s *Service) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.CreateCommentResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := commentContent(req.GetContent())
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.getVisiblePost(ctx, req.GetPostId(), userID)
+	if err != nil {
+		return nil, err
+	}
+	postID := post.ID
+
+	// a reply must point to a comment of the same post
+	var parentID primitive.ObjectID
+	if req.GetParentId() != "" {
+		parentID, err = primitive.ObjectIDFromHex(req.GetParentId())
+		if err != nil {
+			return nil, ErrInvalidObjectID
+		}
+
+		parent, err := s.commentDAO.Get(ctx, parentID)
+		if err != nil {
+			if errors.Is(err, dao.ErrCommentNotFound) {
+				return nil, ErrCommentNotFound
+			}
+
+			return nil, err
+		}
+
+		if parent.PostID != postID {
+			return nil, ErrCommentParentMismatch
+		}
+	}
+
+	comment := &dao.Comment{
+		PostID:    postID,
+		UserID:    userID,
+		ParentID:  parentID,
+		Content:   content,
+		CreatedAT: time.Now(),
+		UpdatedAT: time.Now(),
+	}
+
+	commentID, err := s.commentDAO.Create(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateCommentResponse{CommentId: commentID.Hex()}, nil
+}
+
+func (s *Service) ListCommentsByPost(ctx context.Context, req *pb.ListCommentsByPostRequest) (*pb.ListCommentsByPostResponse, error) {
+	// the comments of drafts and scheduled posts are hidden along with the post
+	viewerID, _ := getOptionalUserIDFromMetadata(ctx)
+	post, err := s.getVisiblePost(ctx, req.GetPostId(), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentDAO.ListByPostID(ctx, post.ID, pageLimit(req.GetLimit()), req.GetSkip())
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(comments))
+	for _, comment := range comments {
+		userIDs = append(userIDs, comment.UserID)
+	}
+
+	userNames, err := s.getUserNames(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pbComments := make([]*pb.CommentInfo, 0, len(comments))
+	for _, comment := range comments {
+		pbComments = append(pbComments, comment.ToProto(userNames[comment.UserID]))
+	}
+
+	return &pb.ListCommentsByPostResponse{Comments: pbComments}, nil
+}
+
+func (s *Service) UpdateComment(ctx context.Context, req *pb.UpdateCommentRequest) (*pb.UpdateCommentResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(req.GetCommentId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	content, err := commentContent(req.GetContent())
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &dao.Comment{
+		ID:      commentID,
+		UserID:  userID,
+		Content: content,
+	}
+
+	if err := s.commentDAO.UpdateContent(ctx, comment); err != nil {
+		if errors.Is(err, dao.ErrCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+
+		return nil, err
+	}
+
+	return &pb.UpdateCommentResponse{}, nil
+}
+
+func (s *Service) DeleteComment(ctx context.Context, req *pb.DeleteCommentRequest) (*pb.DeleteCommentResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commentID, err := primitive.ObjectIDFromHex(req.GetCommentId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	// editors can delete the comments of everyone
+	ownerID := userID
+	if getRoleFromMetadata(ctx).AtLeast(authkit.RoleEditor) {
+		comment, err := s.commentDAO.Get(ctx, commentID)
+		if err != nil {
+			if errors.Is(err, dao.ErrCommentNotFound) {
+				return nil, ErrCommentNotFound
+			}
+
+			return nil, err
+		}
+		ownerID = comment.UserID
+	}
+
+	if err := s.commentDAO.Delete(ctx, commentID, ownerID); err != nil {
+		if errors.Is(err, dao.ErrCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+
+		return nil, err
+	}
+
+	return &pb.DeleteCommentResponse{}, nil
+}
+
+// commentContent trims the surrounding whitespace of the content of a comment, which must not be left empty.
+func commentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if n := utf8.RuneCountInString(content); n == 0 || n > maxCommentContentLength {
+		return "", ErrInvalidCommentContent
+	}
+
+	return content, nil
+}
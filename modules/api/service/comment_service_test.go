@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestListCommentsByPostHidesCommentsOfHiddenPosts(t *testing.T) {
+	author := &dao.User{ID: primitive.NewObjectID(), Name: "author"}
+	reader := primitive.NewObjectID()
+	asUser := func(userID primitive.ObjectID) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", userID.Hex(), "role", string(authkit.RoleReader)))
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		status  dao.PostStatus
+		visible bool
+	}{
+		{name: "published", ctx: context.Background(), status: dao.PostStatusPublished, visible: true},
+		{name: "anonymous on a draft", ctx: context.Background(), status: dao.PostStatusDraft},
+		{name: "anonymous on a scheduled post", ctx: context.Background(), status: dao.PostStatusScheduled},
+		{name: "other user on an archived post", ctx: asUser(reader), status: dao.PostStatusArchived},
+		{name: "author on a draft", ctx: asUser(author.ID), status: dao.PostStatusDraft, visible: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			post := &dao.Post{ID: primitive.NewObjectID(), UserID: author.ID, Status: test.status}
+			commentDAO := &fakeCommentDAO{comments: []*dao.Comment{{ID: primitive.NewObjectID(), PostID: post.ID, UserID: author.ID, Content: "hidden"}}}
+			s := &Service{postDAO: newFakePostDAO(post), commentDAO: commentDAO, userDAO: newFakeUserDAO(author)}
+
+			resp, err := s.ListCommentsByPost(test.ctx, &pb.ListCommentsByPostRequest{PostId: post.ID.Hex()})
+			if test.visible {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.GetComments()) != 1 {
+					t.Errorf("listed %d comments, want 1", len(resp.GetComments()))
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrPostNotFound) {
+				t.Errorf("ListCommentsByPost returned %v, want %v", err, ErrPostNotFound)
+			}
+			if commentDAO.calls != 0 {
+				t.Errorf("read the comments of a hidden post %d times", commentDAO.calls)
+			}
+		})
+	}
+}
+
+func TestCommentContentValidation(t *testing.T) {
+	author := &dao.User{ID: primitive.NewObjectID(), Name: "author"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", author.ID.Hex(), "role", string(authkit.RoleReader)))
+
+	tests := []struct {
+		name    string
+		content string
+		// want is the stored content, empty if the content is rejected
+		want string
+	}{
+		{name: "empty", content: ""},
+		{name: "whitespace", content: " \t\n "},
+		{name: "too long", content: strings.Repeat("a", maxCommentContentLength+1)},
+		// the length counts characters, not bytes
+		{name: "longest", content: strings.Repeat("é", maxCommentContentLength), want: strings.Repeat("é", maxCommentContentLength)},
+		{name: "trimmed", content: "\n nice post \n", want: "nice post"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			post := &dao.Post{ID: primitive.NewObjectID(), UserID: author.ID, Status: dao.PostStatusPublished}
+			commentDAO := &fakeCommentDAO{}
+			s := &Service{postDAO: newFakePostDAO(post), commentDAO: commentDAO, userDAO: newFakeUserDAO(author)}
+
+			created, createErr := s.CreateComment(ctx, &pb.CreateCommentRequest{PostId: post.ID.Hex(), Content: test.content})
+
+			// the content of an existing comment is checked the same way
+			existing := &dao.Comment{ID: primitive.NewObjectID(), PostID: post.ID, UserID: author.ID, Content: "before"}
+			commentDAO.comments = append(commentDAO.comments, existing)
+			_, updateErr := s.UpdateComment(ctx, &pb.UpdateCommentRequest{CommentId: existing.ID.Hex(), Content: test.content})
+
+			if test.want == "" {
+				for method, err := range map[string]error{"CreateComment": createErr, "UpdateComment": updateErr} {
+					if !errors.Is(err, ErrInvalidCommentContent) {
+						t.Errorf("%s returned %v, want %v", method, err, ErrInvalidCommentContent)
+					}
+				}
+				if commentDAO.calls != 0 {
+					t.Errorf("wrote %d invalid comments", commentDAO.calls)
+				}
+				return
+			}
+
+			if createErr != nil || updateErr != nil {
+				t.Fatalf("CreateComment returned %v and UpdateComment %v", createErr, updateErr)
+			}
+			for _, comment := range commentDAO.comments {
+				if comment.Content != test.want {
+					t.Errorf("comment %s stored %.20q, want %.20q", comment.ID.Hex(), comment.Content, test.want)
+				}
+			}
+			if commentDAO.comments[0].ID.Hex() != created.GetCommentId() {
+				t.Errorf("created comment %s, want %s", created.GetCommentId(), commentDAO.comments[0].ID.Hex())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/mailkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmailVerificationConfig struct {
+	LinkURL string `long:"link_url" env:"LINK_URL" description:"page of the frontend verifying the email, the token is added as the token query parameter" default:"http://localhost:3000/verify-email"`
+}
+
+// SendEmailVerification mails the caller a new link to verify their email.
+func (s *Service) SendEmailVerification(ctx context.Context, req *pb.SendEmailVerificationRequest) (*pb.SendEmailVerificationResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDAO.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if user.Email == "" {
+		return nil, ErrEmailNotSet
+	}
+	if user.EmailVerified {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &pb.SendEmailVerificationResponse{}, nil
+}
+
+func (s *Service) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	payload, err := s.jwtManager.VerifyEmailVerification(req.GetToken())
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	userID, err := primitive.ObjectIDFromHex(payload.UserID)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	// the link is for the email the user had when it was sent
+	if err := s.userDAO.VerifyEmail(ctx, userID, payload.Email); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	return &pb.VerifyEmailResponse{}, nil
+}
+
+func (s *Service) sendEmailVerification(ctx context.Context, user *dao.User) error {
+	token, err := s.jwtManager.GenerateEmailVerification(user.ID.Hex(), user.Email)
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.emailConf.LinkURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return s.mailer.Send(ctx, &mailkit.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\r\n\r\nOpen the link below to verify your email.\r\n\r\n%s\r\n\r\n"+
+				"If you did not add this email to your account, you can ignore this mail.\r\n",
+			user.Name, link,
+		),
+	})
+}
+
+// normalizeEmail lowercases the email so that it is unique regardless of case, empty emails are allowed.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", nil
+	}
+
+	// display names are not accepted, only the bare address
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
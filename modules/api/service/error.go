@@ -6,11 +6,41 @@ import (
 )
 
 var (
-	ErrInvalidObjectID      = status.Errorf(codes.InvalidArgument, "invalid ObjectID")
-	ErrPostNotFound         = status.Errorf(codes.NotFound, "post not found")
-	ErrUserNotFound         = status.Errorf(codes.NotFound, "user not found")
-	ErrToHashPWD            = status.Errorf(codes.InvalidArgument, "hash password failed")
-	ErrWrongPWD             = status.Errorf(codes.PermissionDenied, "wrong password")
-	ErrMetadataNotProivided = status.Errorf(codes.InvalidArgument, "meatadata not provided")
-	ErrUserAlreadyExists    = status.Errorf(codes.AlreadyExists, "user account already exists")
+	ErrInvalidObjectID          = status.Errorf(codes.InvalidArgument, "invalid ObjectID")
+	ErrPostNotFound             = status.Errorf(codes.NotFound, "post not found")
+	ErrUserNotFound             = status.Errorf(codes.NotFound, "user not found")
+	ErrToHashPWD                = status.Errorf(codes.InvalidArgument, "hash password failed")
+	ErrWrongPWD                 = status.Errorf(codes.PermissionDenied, "wrong password")
+	ErrInvalidCredentials       = status.Errorf(codes.Unauthenticated, "invalid account or password")
+	ErrMetadataNotProivided     = status.Errorf(codes.InvalidArgument, "meatadata not provided")
+	ErrUserAlreadyExists        = status.Errorf(codes.AlreadyExists, "user account already exists")
+	ErrCommentNotFound          = status.Errorf(codes.NotFound, "comment not found")
+	ErrCommentParentMismatch    = status.Errorf(codes.InvalidArgument, "parent comment belongs to another post")
+	ErrInvalidCommentContent    = status.Errorf(codes.InvalidArgument, "comment content must be 1 to 10000 characters")
+	ErrInvalidPostStatus        = status.Errorf(codes.InvalidArgument, "invalid post status")
+	ErrPublishAtRequired        = status.Errorf(codes.InvalidArgument, "publish_at is required for scheduled posts")
+	ErrNotPostAuthor            = status.Errorf(codes.PermissionDenied, "only the author or an editor can access the post revisions")
+	ErrRevisionNotFound         = status.Errorf(codes.NotFound, "revision not found")
+	ErrInvalidPageToken         = status.Errorf(codes.InvalidArgument, "invalid page token")
+	ErrFollowSelf               = status.Errorf(codes.InvalidArgument, "users can not follow themselves")
+	ErrInvalidRefreshToken      = status.Errorf(codes.Unauthenticated, "refresh token is invalid")
+	ErrPermissionDenied         = status.Errorf(codes.PermissionDenied, "permission denied")
+	ErrInvalidUserRole          = status.Errorf(codes.InvalidArgument, "invalid user role")
+	ErrEmptyPassword            = status.Errorf(codes.InvalidArgument, "password must not be empty")
+	ErrInvalidResetToken        = status.Errorf(codes.InvalidArgument, "password reset token is invalid or expired")
+	ErrTOTPAlreadyEnabled       = status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled          = status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	ErrInvalidTOTPCode          = status.Errorf(codes.InvalidArgument, "invalid two-factor code")
+	ErrInvalidChallenge         = status.Errorf(codes.Unauthenticated, "login challenge is invalid or expired")
+	ErrInvalidTokenName         = status.Errorf(codes.InvalidArgument, "access token name must be 1 to 64 characters")
+	ErrInvalidTokenScope        = status.Errorf(codes.InvalidArgument, "invalid access token scope")
+	ErrInvalidTokenExpiry       = status.Errorf(codes.InvalidArgument, "access token expiry must be in the future")
+	ErrAccessTokenNotFound      = status.Errorf(codes.NotFound, "access token not found")
+	ErrOIDCDisabled             = status.Errorf(codes.FailedPrecondition, "OpenID Connect login is disabled")
+	ErrInvalidIDToken           = status.Errorf(codes.Unauthenticated, "ID token is invalid")
+	ErrInvalidEmail             = status.Errorf(codes.InvalidArgument, "invalid email")
+	ErrEmailAlreadyUsed         = status.Errorf(codes.AlreadyExists, "email is used by another user")
+	ErrEmailNotSet              = status.Errorf(codes.FailedPrecondition, "user has no email")
+	ErrEmailAlreadyVerified     = status.Errorf(codes.FailedPrecondition, "email is already verified")
+	ErrInvalidVerificationToken = status.Errorf(codes.InvalidArgument, "email verification token is invalid or expired")
 )
@@ -0,0 +1,661 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/mailkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The fakes keep the records in memory and implement the methods the tests use, calling any other
+// method panics on the nil embedded interface.
+
+type fakeUserDAO struct {
+	dao.UserDAO
+
+	mu    sync.Mutex
+	users map[primitive.ObjectID]*dao.User
+	// calls counts the round trips, as each call to the database would be one
+	calls int
+	// latency is added to every call, as the round trip to the database would
+	latency time.Duration
+}
+
+func newFakeUserDAO(users ...*dao.User) *fakeUserDAO {
+	f := &fakeUserDAO{users: make(map[primitive.ObjectID]*dao.User)}
+	for _, user := range users {
+		f.users[user.ID] = user
+	}
+
+	return f
+}
+
+// roundTrip counts a call and waits for the latency, f.mu must be held.
+func (f *fakeUserDAO) roundTrip() {
+	f.calls++
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+}
+
+func (f *fakeUserDAO) Get(ctx context.Context, id primitive.ObjectID) (*dao.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	user, ok := f.users[id]
+	if !ok {
+		return nil, dao.ErrUserNotFound
+	}
+	copied := *user
+
+	return &copied, nil
+}
+
+func (f *fakeUserDAO) GetMany(ctx context.Context, ids []primitive.ObjectID) ([]*dao.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	users := make([]*dao.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := f.users[id]; ok {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+
+	return users, nil
+}
+
+func (f *fakeUserDAO) GetByUserAccount(ctx context.Context, account string) (*dao.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	for _, user := range f.users {
+		if user.Account == account {
+			copied := *user
+			return &copied, nil
+		}
+	}
+
+	return nil, dao.ErrUserNotFound
+}
+
+func (f *fakeUserDAO) Create(ctx context.Context, user *dao.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	for _, existing := range f.users {
+		if existing.Account == user.Account {
+			return dao.ErrAccountExists
+		}
+		if user.Email != "" && existing.Email == user.Email {
+			return dao.ErrEmailExists
+		}
+	}
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	copied := *user
+	f.users[user.ID] = &copied
+
+	return nil
+}
+
+func (f *fakeUserDAO) UseTOTPCounter(ctx context.Context, id primitive.ObjectID, counter int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	user, ok := f.users[id]
+	if !ok || user.TOTPCounter >= counter {
+		return false, nil
+	}
+	user.TOTPCounter = counter
+
+	return true, nil
+}
+
+func (f *fakeUserDAO) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	user, ok := f.users[id]
+	if !ok {
+		return false, nil
+	}
+	for i, hash := range user.RecoveryCodes {
+		if hash == codeHash {
+			user.RecoveryCodes = append(user.RecoveryCodes[:i:i], user.RecoveryCodes[i+1:]...)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type fakePostDAO struct {
+	dao.PostDAO
+
+	mu    sync.Mutex
+	posts map[primitive.ObjectID]*dao.Post
+	// updateErr is returned by UpdateContent instead of updating, as a concurrent delete would
+	updateErr error
+}
+
+func newFakePostDAO(posts ...*dao.Post) *fakePostDAO {
+	f := &fakePostDAO{posts: make(map[primitive.ObjectID]*dao.Post)}
+	for _, post := range posts {
+		f.posts[post.ID] = post
+	}
+
+	return f
+}
+
+func (f *fakePostDAO) Get(ctx context.Context, id primitive.ObjectID) (*dao.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	post, ok := f.posts[id]
+	if !ok {
+		return nil, dao.ErrPostNotFound
+	}
+	copied := *post
+
+	return &copied, nil
+}
+
+func (f *fakePostDAO) UpdateContent(ctx context.Context, post *dao.Post) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	existing, ok := f.posts[post.ID]
+	if !ok || existing.UserID != post.UserID {
+		return dao.ErrPostNotFound
+	}
+	existing.Title = post.Title
+	existing.Content = post.Content
+	existing.Image = post.Image
+	existing.Tags = post.Tags
+
+	return nil
+}
+
+func (f *fakePostDAO) UpdateLikes(ctx context.Context, id primitive.ObjectID, delta int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	post, ok := f.posts[id]
+	if !ok {
+		return 0, dao.ErrPostNotFound
+	}
+	post.Likes += delta
+
+	return post.Likes, nil
+}
+
+type fakeLikeDAO struct {
+	dao.LikeDAO
+
+	mu    sync.Mutex
+	likes []*dao.Like
+	// calls counts the calls, so that tests can check the likes were not touched
+	calls int
+}
+
+func (f *fakeLikeDAO) Create(ctx context.Context, postID, userID primitive.ObjectID) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	for _, like := range f.likes {
+		if like.PostID == postID && like.UserID == userID {
+			return false, nil
+		}
+	}
+	f.likes = append(f.likes, &dao.Like{ID: primitive.NewObjectID(), PostID: postID, UserID: userID})
+
+	return true, nil
+}
+
+func (f *fakeLikeDAO) ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*dao.Like, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	var likes []*dao.Like
+	for _, like := range f.likes {
+		if like.PostID == postID {
+			likes = append(likes, like)
+		}
+	}
+
+	return likes, nil
+}
+
+type fakeRevisionDAO struct {
+	dao.RevisionDAO
+
+	mu        sync.Mutex
+	revisions []*dao.PostRevision
+}
+
+func (f *fakeRevisionDAO) Create(ctx context.Context, revision *dao.PostRevision) (primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	revision.ID = primitive.NewObjectID()
+	copied := *revision
+	f.revisions = append(f.revisions, &copied)
+
+	return revision.ID, nil
+}
+
+type fakeIdentityDAO struct {
+	dao.IdentityDAO
+
+	mu         sync.Mutex
+	identities []*dao.Identity
+}
+
+func (f *fakeIdentityDAO) Get(ctx context.Context, issuer, subject string) (*dao.Identity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, identity := range f.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+
+	return nil, dao.ErrIdentityNotFound
+}
+
+func (f *fakeIdentityDAO) Create(ctx context.Context, identity *dao.Identity) (primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, existing := range f.identities {
+		if existing.Issuer == identity.Issuer && existing.Subject == identity.Subject {
+			return primitive.NilObjectID, dao.ErrIdentityExists
+		}
+	}
+
+	identity.ID = primitive.NewObjectID()
+	copied := *identity
+	f.identities = append(f.identities, &copied)
+
+	return identity.ID, nil
+}
+
+type fakeSessionDAO struct {
+	dao.SessionDAO
+
+	mu       sync.Mutex
+	sessions []*dao.Session
+}
+
+func (f *fakeSessionDAO) Create(ctx context.Context, session *dao.Session) (primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session.ID = primitive.NewObjectID()
+	copied := *session
+	f.sessions = append(f.sessions, &copied)
+
+	return session.ID, nil
+}
+
+func (f *fakeUserDAO) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roundTrip()
+	user, ok := f.users[id]
+	if !ok {
+		return dao.ErrUserNotFound
+	}
+	user.Password = password
+
+	return nil
+}
+
+func (f *fakeSessionDAO) Rotate(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*dao.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, session := range f.sessions {
+		if session.TokenHash == tokenHash && time.Now().Before(session.ExpiresAT) {
+			session.PreviousTokenHash = tokenHash
+			session.TokenHash = newTokenHash
+			session.ExpiresAT = expiresAt
+			copied := *session
+			return &copied, nil
+		}
+	}
+
+	return nil, dao.ErrSessionNotFound
+}
+
+func (f *fakeSessionDAO) GetByPreviousTokenHash(ctx context.Context, tokenHash string) (*dao.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, session := range f.sessions {
+		if session.PreviousTokenHash == tokenHash {
+			copied := *session
+			return &copied, nil
+		}
+	}
+
+	return nil, dao.ErrSessionNotFound
+}
+
+func (f *fakeSessionDAO) ListIDsByUserID(ctx context.Context, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []primitive.ObjectID
+	for _, session := range f.sessions {
+		if session.UserID == userID {
+			ids = append(ids, session.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *fakeSessionDAO) Delete(ctx context.Context, id primitive.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, session := range f.sessions {
+		if session.ID == id {
+			f.sessions = append(f.sessions[:i], f.sessions[i+1:]...)
+			return nil
+		}
+	}
+
+	return dao.ErrSessionNotFound
+}
+
+func (f *fakeSessionDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sessions := f.sessions[:0]
+	for _, session := range f.sessions {
+		if session.UserID != userID {
+			sessions = append(sessions, session)
+		}
+	}
+	f.sessions = sessions
+
+	return nil
+}
+
+type fakePasswordResetDAO struct {
+	dao.PasswordResetDAO
+
+	mu     sync.Mutex
+	resets []*dao.PasswordReset
+}
+
+func (f *fakePasswordResetDAO) Create(ctx context.Context, reset *dao.PasswordReset) (primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reset.ID = primitive.NewObjectID()
+	copied := *reset
+	f.resets = append(f.resets, &copied)
+
+	return reset.ID, nil
+}
+
+func (f *fakePasswordResetDAO) Consume(ctx context.Context, tokenHash string) (*dao.PasswordReset, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, reset := range f.resets {
+		if reset.TokenHash == tokenHash && time.Now().Before(reset.ExpiresAT) {
+			f.resets = append(f.resets[:i], f.resets[i+1:]...)
+			return reset, nil
+		}
+	}
+
+	return nil, dao.ErrPasswordResetNotFound
+}
+
+func (f *fakePasswordResetDAO) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resets := f.resets[:0]
+	for _, reset := range f.resets {
+		if reset.UserID != userID {
+			resets = append(resets, reset)
+		}
+	}
+	f.resets = resets
+
+	return nil
+}
+
+type fakeAccessTokenStore struct {
+	authkit.AccessTokenStore
+
+	mu     sync.Mutex
+	tokens []*authkit.AccessToken
+}
+
+func (f *fakeAccessTokenStore) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tokens := f.tokens[:0]
+	for _, token := range f.tokens {
+		if token.UserID != userID {
+			tokens = append(tokens, token)
+		}
+	}
+	f.tokens = tokens
+
+	return nil
+}
+
+type fakeDenylist struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+}
+
+func (f *fakeDenylist) Revoke(ctx context.Context, ids ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.revoked == nil {
+		f.revoked = make(map[string]bool)
+	}
+	for _, id := range ids {
+		f.revoked[id] = true
+	}
+
+	return nil
+}
+
+func (f *fakeDenylist) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range ids {
+		if f.revoked[id] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type fakeMailer struct {
+	mu       sync.Mutex
+	messages []*mailkit.Message
+}
+
+func (f *fakeMailer) Send(ctx context.Context, msg *mailkit.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = append(f.messages, msg)
+
+	return nil
+}
+
+type fakeCommentDAO struct {
+	dao.CommentDAO
+
+	mu       sync.Mutex
+	comments []*dao.Comment
+	// calls counts the calls, so that tests can check the comments were not touched
+	calls int
+}
+
+func (f *fakeCommentDAO) ListByPostID(ctx context.Context, postID primitive.ObjectID, limit, skip int64) ([]*dao.Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	var comments []*dao.Comment
+	for _, comment := range f.comments {
+		if comment.PostID == postID {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+
+	return comments, nil
+}
+
+func (f *fakeCommentDAO) Create(ctx context.Context, comment *dao.Comment) (primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	comment.ID = primitive.NewObjectID()
+	copied := *comment
+	f.comments = append(f.comments, &copied)
+
+	return comment.ID, nil
+}
+
+func (f *fakeCommentDAO) UpdateContent(ctx context.Context, comment *dao.Comment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	for _, stored := range f.comments {
+		if stored.ID == comment.ID && stored.UserID == comment.UserID {
+			stored.Content = comment.Content
+			return nil
+		}
+	}
+
+	return dao.ErrCommentNotFound
+}
+
+type fakeViewDAO struct {
+	dao.ViewDAO
+
+	mu      sync.Mutex
+	viewers map[string]bool
+}
+
+func (f *fakeViewDAO) Record(ctx context.Context, postID primitive.ObjectID, viewer string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.viewers == nil {
+		f.viewers = make(map[string]bool)
+	}
+	key := postID.Hex() + "|" + viewer
+	if f.viewers[key] {
+		return false, nil
+	}
+	f.viewers[key] = true
+
+	return true, nil
+}
+
+type fakeLoginAttemptDAO struct {
+	mu       sync.Mutex
+	failures map[string]int64
+	// locks holds when the lock of each key ends, and lockouts every lockout given in order
+	locks    map[string]time.Time
+	lockouts []time.Duration
+}
+
+func newFakeLoginAttemptDAO() *fakeLoginAttemptDAO {
+	return &fakeLoginAttemptDAO{failures: make(map[string]int64), locks: make(map[string]time.Time)}
+}
+
+func (f *fakeLoginAttemptDAO) LockedFor(ctx context.Context, keys ...string) (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var longest time.Duration
+	for _, key := range keys {
+		if d := time.Until(f.locks[key]); d > longest {
+			longest = d
+		}
+	}
+
+	return longest, nil
+}
+
+func (f *fakeLoginAttemptDAO) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[key]++
+
+	return f.failures[key], nil
+}
+
+func (f *fakeLoginAttemptDAO) Lock(ctx context.Context, key string, d time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.locks[key] = time.Now().Add(d)
+	f.lockouts = append(f.lockouts, d)
+
+	return nil
+}
+
+func (f *fakeLoginAttemptDAO) Reset(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.failures, key)
+	delete(f.locks, key)
+
+	return nil
+}
+
+// unlock ends every lock, as if their time had passed.
+func (f *fakeLoginAttemptDAO) unlock() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.locks = make(map[string]time.Time)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// maxLockoutShift caps the exponent of the backoff so that it can not overflow.
+const maxLockoutShift = 20
+
+type LoginLimitConfig struct {
+	AccountAttempts int64         `long:"account_attempts" env:"ACCOUNT_ATTEMPTS" description:"failed logins of an account before it is locked" default:"5"`
+	AddrAttempts    int64         `long:"addr_attempts" env:"ADDR_ATTEMPTS" description:"failed logins from a client address before it is locked" default:"20"`
+	Window          time.Duration `long:"window" env:"WINDOW" description:"failed logins are forgotten after no failure for the window" default:"1h"`
+	BaseLockout     time.Duration `long:"base_lockout" env:"BASE_LOCKOUT" description:"first lockout, it doubles on every further failure" default:"30s"`
+	MaxLockout      time.Duration `long:"max_lockout" env:"MAX_LOCKOUT" description:"longest lockout" default:"15m"`
+	TrustedProxies  int           `long:"trusted_proxies" env:"TRUSTED_PROXIES" description:"number of proxies in front of the gateway, used to find the client address" default:"0"`
+}
+
+func loginAccountKey(account string) string {
+	return "account:" + strings.ToLower(account)
+}
+
+func loginAddrKey(addr string) string {
+	return "addr:" + addr
+}
+
+// checkLoginLock fails if the account or the client address is locked out.
+func (s *Service) checkLoginLock(ctx context.Context, account, addr string) error {
+	lockedFor, err := s.loginAttemptDAO.LockedFor(ctx, loginAccountKey(account), loginAddrKey(addr))
+	if err != nil {
+		return err
+	}
+
+	if lockedFor > 0 {
+		return errTooManyLoginAttempts(lockedFor)
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login of the account from the client address,
+// and locks out whichever of them has failed too often.
+func (s *Service) recordLoginFailure(ctx context.Context, account, addr string) error {
+	for _, limit := range []struct {
+		key      string
+		attempts int64
+	}{
+		{loginAccountKey(account), s.loginConf.AccountAttempts},
+		{loginAddrKey(addr), s.loginConf.AddrAttempts},
+	} {
+		failures, err := s.loginAttemptDAO.RecordFailure(ctx, limit.key, s.loginConf.Window)
+		if err != nil {
+			return err
+		}
+
+		if failures < limit.attempts {
+			continue
+		}
+
+		lockout := s.lockoutFor(failures - limit.attempts)
+		if err := s.loginAttemptDAO.Lock(ctx, limit.key, lockout); err != nil {
+			return err
+		}
+
+		s.logger.Warn("lock out login",
+			zap.String("key", limit.key),
+			zap.String("user_account", account),
+			zap.String("client_addr", addr),
+			zap.Int64("failures", failures),
+			zap.Duration("lockout", lockout),
+		)
+	}
+
+	return nil
+}
+
+// lockoutFor doubles the base lockout for every failure beyond the allowed attempts.
+func (s *Service) lockoutFor(extraFailures int64) time.Duration {
+	if extraFailures > maxLockoutShift {
+		extraFailures = maxLockoutShift
+	}
+
+	lockout := s.loginConf.BaseLockout << extraFailures
+	if lockout > s.loginConf.MaxLockout || lockout <= 0 {
+		lockout = s.loginConf.MaxLockout
+	}
+
+	return lockout
+}
+
+// getDummyHash returns a hash of the current algorithm to verify passwords of unknown accounts against.
+func (s *Service) getDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.hasher.Hash("dummy password")
+	})
+
+	return s.dummyHash
+}
+
+func errTooManyLoginAttempts(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "too many failed logins, try again later")
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/passwordkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type loginTest struct {
+	svc             *Service
+	loginAttemptDAO *fakeLoginAttemptDAO
+}
+
+func newLoginTest(t *testing.T, users ...*dao.User) *loginTest {
+	t.Helper()
+
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	hasher, err := passwordkit.NewHasher(&passwordkit.HasherConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if user.Password, err = hasher.Hash(user.Password); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt := &loginTest{loginAttemptDAO: newFakeLoginAttemptDAO()}
+	tt.svc = &Service{
+		userDAO:         newFakeUserDAO(users...),
+		sessionDAO:      &fakeSessionDAO{},
+		loginAttemptDAO: tt.loginAttemptDAO,
+		hasher:          hasher,
+		jwtManager: authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+			SecretKey:            "secret",
+			TokenDuration:        15 * time.Minute,
+			RefreshTokenDuration: time.Hour,
+		}),
+		loginConf: &LoginLimitConfig{
+			AccountAttempts: 3,
+			AddrAttempts:    5,
+			Window:          time.Hour,
+			BaseLockout:     30 * time.Second,
+			MaxLockout:      2 * time.Minute,
+		},
+		logger: logger,
+	}
+
+	return tt
+}
+
+func (tt *loginTest) login(account, password, addr string) error {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", addr))
+	_, err := tt.svc.Login(ctx, &pb.LoginRequest{UserAccount: account, UserPassword: password})
+
+	return err
+}
+
+// retryAfter returns the delay of a lockout error, or fails the test for any other error.
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("login returned %v, want a lockout", err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+
+	t.Fatalf("the lockout %v has no retry delay", err)
+	return 0
+}
+
+func TestLoginLocksOutAccount(t *testing.T) {
+	tt := newLoginTest(t, &dao.User{ID: primitive.NewObjectID(), Account: "alice", Password: "password"})
+
+	for i := 0; i < 3; i++ {
+		if err := tt.login("alice", "wrong", "203.0.113.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failed login %d returned %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+
+	// the right password does not help once locked, from any address and in any case
+	for _, account := range []string{"alice", "ALICE"} {
+		if d := retryAfter(t, tt.login(account, "password", "198.51.100.1")); d <= 0 || d > 30*time.Second {
+			t.Errorf("locked out for %v, want up to the base lockout", d)
+		}
+	}
+
+	tt.loginAttemptDAO.unlock()
+	if err := tt.login("alice", "password", "203.0.113.1"); err != nil {
+		t.Fatalf("login after the lockout returned %v", err)
+	}
+
+	// a successful login forgets the failures of the account
+	if err := tt.login("alice", "wrong", "203.0.113.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("failed login returned %v, want %v", err, ErrInvalidCredentials)
+	}
+	if err := tt.login("alice", "password", "203.0.113.1"); err != nil {
+		t.Errorf("login after one failure returned %v, the failures before the success were counted", err)
+	}
+}
+
+func TestLoginLockoutBacksOff(t *testing.T) {
+	tt := newLoginTest(t, &dao.User{ID: primitive.NewObjectID(), Account: "alice", Password: "password"})
+
+	// every failure beyond the allowed attempts doubles the lockout up to the maximum
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i := 0; i < 2+len(want); i++ {
+		tt.loginAttemptDAO.unlock()
+		// a new address each time, so that only the account is locked out
+		if err := tt.login("alice", "wrong", primitive.NewObjectID().Hex()); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failed login %d returned %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+
+	if len(tt.loginAttemptDAO.lockouts) != len(want) {
+		t.Fatalf("locked out %v, want %v", tt.loginAttemptDAO.lockouts, want)
+	}
+	for i, lockout := range tt.loginAttemptDAO.lockouts {
+		if lockout != want[i] {
+			t.Errorf("lockout %d is %v, want %v", i+1, lockout, want[i])
+		}
+	}
+}
+
+func TestLoginLocksOutAddress(t *testing.T) {
+	tt := newLoginTest(t, &dao.User{ID: primitive.NewObjectID(), Account: "alice", Password: "password"})
+
+	// guessing one password over many accounts, unknown ones included, locks the address out
+	for i := 0; i < 5; i++ {
+		account := primitive.NewObjectID().Hex()
+		if err := tt.login(account, "wrong", "203.0.113.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failed login %d returned %v, want %v", i+1, err, ErrInvalidCredentials)
+		}
+	}
+
+	retryAfter(t, tt.login("alice", "password", "203.0.113.1"))
+	if err := tt.login("alice", "password", "198.51.100.1"); err != nil {
+		t.Errorf("login from another address returned %v", err)
+	}
+}
+
+func TestLockoutFor(t *testing.T) {
+	s := &Service{loginConf: &LoginLimitConfig{BaseLockout: 30 * time.Second, MaxLockout: 15 * time.Minute}}
+
+	tests := []struct {
+		extraFailures int64
+		want          time.Duration
+	}{
+		{extraFailures: 0, want: 30 * time.Second},
+		{extraFailures: 1, want: time.Minute},
+		{extraFailures: 4, want: 8 * time.Minute},
+		{extraFailures: 5, want: 15 * time.Minute},
+		// the shift is capped, so that it can not overflow into a negative lockout
+		{extraFailures: 64, want: 15 * time.Minute},
+		{extraFailures: 1 << 40, want: 15 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if got := s.lockoutFor(test.extraFailures); got != test.want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", test.extraFailures, got, test.want)
+		}
+	}
+}
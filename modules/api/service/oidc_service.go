@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	maxOIDCAccountLength = 32
+	// oidcAccountAttempts is how many random suffixes are tried when the account of a new user is taken
+	oidcAccountAttempts = 5
+)
+
+// LoginWithOIDC signs in the user linked to the subject of an ID token of the OpenID Connect provider,
+// the user is created on the first login. The token must carry the nonce of the login flow.
+func (s *Service) LoginWithOIDC(ctx context.Context, req *pb.LoginWithOIDCRequest) (*pb.LoginResponse, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	claims, err := s.oidc.VerifyIDToken(ctx, req.GetIdToken())
+	if err != nil {
+		s.logger.Warn("failed to verify ID token", zap.Error(err))
+		return nil, ErrInvalidIDToken
+	}
+	if req.GetNonce() == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(req.GetNonce())) != 1 {
+		s.logger.Warn("ID token nonce mismatch", zap.String("subject", claims.Subject))
+		return nil, ErrInvalidIDToken
+	}
+
+	user, err := s.getOIDCUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// two-factor authentication still applies, the provider may not require a second factor
+	if user.TOTPEnabled {
+		return s.newChallengeResponse(user)
+	}
+
+	return s.newSessionResponse(ctx, user)
+}
+
+// getOIDCUser returns the user linked to the subject, linking a new user first if there is none.
+func (s *Service) getOIDCUser(ctx context.Context, claims *authkit.IDTokenClaims) (*dao.User, error) {
+	identity, err := s.identityDAO.Get(ctx, claims.Issuer, claims.Subject)
+	if errors.Is(err, dao.ErrIdentityNotFound) {
+		// the subject is linked before the user is created, so that concurrent first logins agree on the user
+		identity = &dao.Identity{
+			Issuer:    claims.Issuer,
+			Subject:   claims.Subject,
+			UserID:    primitive.NewObjectID(),
+			CreatedAT: time.Now(),
+		}
+		if _, err := s.identityDAO.Create(ctx, identity); errors.Is(err, dao.ErrIdentityExists) {
+			identity, err = s.identityDAO.Get(ctx, claims.Issuer, claims.Subject)
+			if errors.Is(err, dao.ErrIdentityNotFound) {
+				// the concurrent link was removed in between, the login can be retried
+				return nil, ErrUserNotFound
+			}
+			if err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDAO.Get(ctx, identity.UserID)
+	if !errors.Is(err, dao.ErrUserNotFound) {
+		return user, err
+	}
+
+	// first login, or a previous one failed to create the user
+	user, err = s.newOIDCUser(ctx, identity.UserID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userDAO.Create(ctx, user)
+	if errors.Is(err, dao.ErrEmailExists) {
+		// the email belongs to another user, who has to link it themselves
+		user.Email, user.EmailVerified = "", false
+		err = s.userDAO.Create(ctx, user)
+	}
+	if err != nil {
+		// a concurrent first login may have created it
+		if existing, getErr := s.userDAO.Get(ctx, identity.UserID); getErr == nil {
+			return existing, nil
+		}
+
+		switch {
+		case errors.Is(err, dao.ErrAccountExists):
+			return nil, ErrUserAlreadyExists
+		case errors.Is(err, dao.ErrEmailExists):
+			return nil, ErrEmailAlreadyUsed
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *Service) newOIDCUser(ctx context.Context, id primitive.ObjectID, claims *authkit.IDTokenClaims) (*dao.User, error) {
+	account, err := s.newOIDCAccount(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &dao.User{
+		ID:      id,
+		Account: account,
+		Name:    claims.Name,
+		Role:    authkit.RoleAuthor,
+	}
+	if user.Name == "" {
+		user.Name = account
+	}
+	// unverified addresses are not taken, they could belong to someone else
+	if claims.EmailVerified {
+		if email, err := normalizeEmail(claims.Email); err == nil && email != "" {
+			user.Email = email
+			user.EmailVerified = true
+		}
+	}
+
+	return user, nil
+}
+
+// newOIDCAccount derives a free account from the preferred username or the email address of the subject.
+func (s *Service) newOIDCAccount(ctx context.Context, claims *authkit.IDTokenClaims) (string, error) {
+	base := sanitizeAccount(claims.PreferredUsername)
+	if base == "" {
+		base = sanitizeAccount(strings.SplitN(claims.Email, "@", 2)[0])
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	account := base
+	for i := 0; i < oidcAccountAttempts; i++ {
+		if _, err := s.userDAO.GetByUserAccount(ctx, account); errors.Is(err, dao.ErrUserNotFound) {
+			return account, nil
+		} else if err != nil {
+			return "", err
+		}
+
+		b := make([]byte, 3)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		account = base + "-" + hex.EncodeToString(b)
+	}
+
+	return "", ErrUserAlreadyExists
+}
+
+// sanitizeAccount keeps the lowercase letters, digits, dots, dashes and underscores of s.
+func sanitizeAccount(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if b.Len() >= maxOIDCAccountLength {
+			break
+		}
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.Trim(b.String(), ".-_")
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/authkit/oidctest"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/golang-jwt/jwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type oidcTest struct {
+	svc         *Service
+	iss         *oidctest.Issuer
+	userDAO     *fakeUserDAO
+	identityDAO *fakeIdentityDAO
+	sessionDAO  *fakeSessionDAO
+}
+
+func newOIDCTest(t *testing.T, users ...*dao.User) *oidcTest {
+	t.Helper()
+
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	ctx := logger.WithContext(context.Background())
+
+	iss := oidctest.NewIssuer(t, "blog")
+	tt := &oidcTest{
+		iss:         iss,
+		userDAO:     newFakeUserDAO(users...),
+		identityDAO: &fakeIdentityDAO{},
+		sessionDAO:  &fakeSessionDAO{},
+	}
+	tt.svc = &Service{
+		userDAO:     tt.userDAO,
+		identityDAO: tt.identityDAO,
+		sessionDAO:  tt.sessionDAO,
+		jwtManager: authkit.NewJWTManager(ctx, &authkit.JWTConfig{
+			SecretKey:            "secret",
+			TokenDuration:        15 * time.Minute,
+			RefreshTokenDuration: time.Hour,
+			ChallengeDuration:    5 * time.Minute,
+		}),
+		oidc: authkit.NewOIDCProvider(&authkit.OIDCConfig{
+			IssuerURL: iss.URL,
+			ClientID:  iss.ClientID,
+		}),
+		logger: logger,
+	}
+
+	return tt
+}
+
+func (tt *oidcTest) login(t *testing.T, claims jwt.MapClaims, nonce string) (*pb.LoginResponse, error) {
+	t.Helper()
+
+	return tt.svc.LoginWithOIDC(context.Background(), &pb.LoginWithOIDCRequest{
+		IdToken: tt.iss.Sign(t, claims),
+		Nonce:   nonce,
+	})
+}
+
+func TestLoginWithOIDCLinksSubjectOnce(t *testing.T) {
+	tt := newOIDCTest(t)
+
+	claims := tt.iss.Claims("alice-sub", "nonce")
+	claims["preferred_username"] = "Alice"
+	claims["email"] = "Alice@Example.com"
+	claims["email_verified"] = true
+
+	first, err := tt.login(t, claims, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetToken() == "" || first.GetRefreshToken() == "" {
+		t.Fatalf("no session in %v", first)
+	}
+
+	userID, err := primitive.ObjectIDFromHex(first.GetUserId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := tt.userDAO.Get(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Account != "alice" || user.Email != "alice@example.com" || !user.EmailVerified || user.Role != authkit.RoleAuthor {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	// the same issuer and subject sign in as the same user, whatever the other claims say
+	claims = tt.iss.Claims("alice-sub", "other-nonce")
+	claims["preferred_username"] = "renamed"
+	second, err := tt.login(t, claims, "other-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.GetUserId() != first.GetUserId() {
+		t.Fatalf("second login signed in as %s, want %s", second.GetUserId(), first.GetUserId())
+	}
+
+	if len(tt.identityDAO.identities) != 1 || len(tt.userDAO.users) != 1 || len(tt.sessionDAO.sessions) != 2 {
+		t.Fatalf("got %d identities, %d users and %d sessions, want 1, 1 and 2",
+			len(tt.identityDAO.identities), len(tt.userDAO.users), len(tt.sessionDAO.sessions))
+	}
+
+	// another subject is another user
+	third, err := tt.login(t, tt.iss.Claims("bob-sub", "nonce"), "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.GetUserId() == first.GetUserId() {
+		t.Fatal("another subject signed in as the same user")
+	}
+}
+
+func TestLoginWithOIDCSkipsTakenAccountAndEmail(t *testing.T) {
+	existing := &dao.User{
+		ID:            primitive.NewObjectID(),
+		Account:       "alice",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+	}
+	tt := newOIDCTest(t, existing)
+
+	claims := tt.iss.Claims("alice-sub", "nonce")
+	claims["preferred_username"] = "alice"
+	claims["email"] = "alice@example.com"
+	claims["email_verified"] = true
+
+	resp, err := tt.login(t, claims, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetUserId() == existing.ID.Hex() {
+		t.Fatal("the subject was linked to the user of the same account or email")
+	}
+
+	userID, _ := primitive.ObjectIDFromHex(resp.GetUserId())
+	user, err := tt.userDAO.Get(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Account == "alice" || user.Email != "" || user.EmailVerified {
+		t.Fatalf("unexpected user %+v", user)
+	}
+}
+
+func TestLoginWithOIDCRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		nonce  string
+	}{
+		{
+			name:  "no nonce",
+			nonce: "",
+		},
+		{
+			name:  "bad nonce",
+			nonce: "other-nonce",
+		},
+		{
+			name:   "wrong audience",
+			modify: func(c jwt.MapClaims) { c["aud"] = "other" },
+			nonce:  "nonce",
+		},
+		{
+			name:   "wrong authorized party",
+			modify: func(c jwt.MapClaims) { c["aud"], c["azp"] = []string{"blog", "other"}, "other" },
+			nonce:  "nonce",
+		},
+		{
+			name:   "expired",
+			modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			nonce:  "nonce",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tt := newOIDCTest(t)
+
+			claims := tt.iss.Claims("alice-sub", "nonce")
+			if test.modify != nil {
+				test.modify(claims)
+			}
+
+			_, err := tt.login(t, claims, test.nonce)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidIDToken)
+			}
+			if len(tt.identityDAO.identities) != 0 || len(tt.userDAO.users) != 0 {
+				t.Fatal("a rejected login linked a user")
+			}
+		})
+	}
+}
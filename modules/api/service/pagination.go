@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/alice890308/blog-server/modules/api/dao"
+)
+
+const (
+	defaultPageSize int64 = 20
+	maxPageSize     int64 = 100
+)
+
+// pageLimit applies the server side page size, a limit of 0 must not return the whole collection.
+func pageLimit(limit int64) int64 {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+
+	return limit
+}
+
+func decodePageToken(token string) (*dao.PageCursor, error) {
+	cursor, err := dao.DecodePageCursor(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import "testing"
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		limit int64
+		want  int64
+	}{
+		{limit: 0, want: defaultPageSize},
+		{limit: -1, want: defaultPageSize},
+		{limit: 1, want: 1},
+		{limit: maxPageSize, want: maxPageSize},
+		{limit: maxPageSize + 1, want: maxPageSize},
+		{limit: 1 << 40, want: maxPageSize},
+	}
+
+	for _, test := range tests {
+		if got := pageLimit(test.limit); got != test.want {
+			t.Errorf("pageLimit(%d) = %d, want %d", test.limit, got, test.want)
+		}
+	}
+}
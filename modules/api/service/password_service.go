@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/mailkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasswordResetConfig struct {
+	LinkURL  string        `long:"link_url" env:"LINK_URL" description:"page of the frontend resetting the password, the token is added as the token query parameter" default:"http://localhost:3000/reset-password"`
+	TokenTTL time.Duration `long:"token_ttl" env:"TOKEN_TTL" description:"how long a password reset token is valid" default:"30m"`
+}
+
+func (s *Service) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetNewPassword() == "" {
+		return nil, ErrEmptyPassword
+	}
+
+	user, err := s.userDAO.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// users created by an OpenID Connect login have no password to check until they reset it
+	if user.Password == "" {
+		return nil, ErrWrongPWD
+	}
+	if ok, _, err := s.hasher.Verify(user.Password, req.GetOldPassword()); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrWrongPWD
+	}
+
+	if err := s.setPassword(ctx, user.ID, req.GetNewPassword()); err != nil {
+		return nil, err
+	}
+
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+// RequestPasswordReset mails a reset link to the user, it succeeds for unknown accounts too
+// so that it can not be used to find out which accounts exist.
+func (s *Service) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	user, err := s.userDAO.GetByUserAccount(ctx, req.GetUserAccount())
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return &pb.RequestPasswordResetResponse{}, nil
+		}
+		return nil, err
+	}
+
+	// an unverified address may belong to someone else, who could take the account over with the link
+	if user.Email == "" || !user.EmailVerified {
+		return &pb.RequestPasswordResetResponse{}, nil
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// only the latest requested token is valid
+	if err := s.passwordResetDAO.DeleteByUserID(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, err := s.passwordResetDAO.Create(ctx, &dao.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAT: now.Add(s.resetConf.TokenTTL),
+		CreatedAT: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	link, err := url.Parse(s.resetConf.LinkURL)
+	if err != nil {
+		return nil, err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	if err := s.mailer.Send(ctx, &mailkit.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\r\n\r\nOpen the link below to choose a new password, it expires in %s.\r\n\r\n%s\r\n\r\n"+
+				"If you did not ask to reset your password, you can ignore this mail.\r\n",
+			user.Name, s.resetConf.TokenTTL, link,
+		),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &pb.RequestPasswordResetResponse{}, nil
+}
+
+func (s *Service) ConfirmPasswordReset(ctx context.Context, req *pb.ConfirmPasswordResetRequest) (*pb.ConfirmPasswordResetResponse, error) {
+	if req.GetNewPassword() == "" {
+		return nil, ErrEmptyPassword
+	}
+
+	reset, err := s.passwordResetDAO.Consume(ctx, hashToken(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, dao.ErrPasswordResetNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+
+	if err := s.setPassword(ctx, reset.UserID, req.GetNewPassword()); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+		return nil, err
+	}
+
+	return &pb.ConfirmPasswordResetResponse{}, nil
+}
+
+// setPassword replaces the password of the user, ends all their sessions and deletes their personal access
+// tokens, which whoever knew the old password may have created.
+func (s *Service) setPassword(ctx context.Context, userID primitive.ObjectID, password string) error {
+	hashedPWD, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userDAO.UpdatePassword(ctx, userID, hashedPWD); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.passwordResetDAO.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.accessTokens.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.revokeUserSessions(ctx, userID)
+}
+
+func (s *Service) hashPassword(password string) (string, error) {
+	hashedPWD, err := s.hasher.Hash(password)
+	if err != nil {
+		return "", ErrToHashPWD
+	}
+
+	return hashedPWD, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/passwordkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type passwordTest struct {
+	svc              *Service
+	userDAO          *fakeUserDAO
+	sessionDAO       *fakeSessionDAO
+	passwordResetDAO *fakePasswordResetDAO
+	accessTokens     *fakeAccessTokenStore
+	denylist         *fakeDenylist
+	mailer           *fakeMailer
+}
+
+func newPasswordTest(t *testing.T, users ...*dao.User) *passwordTest {
+	t.Helper()
+
+	hasher, err := passwordkit.NewHasher(&passwordkit.HasherConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := &passwordTest{
+		userDAO:          newFakeUserDAO(users...),
+		sessionDAO:       &fakeSessionDAO{},
+		passwordResetDAO: &fakePasswordResetDAO{},
+		accessTokens:     &fakeAccessTokenStore{},
+		denylist:         &fakeDenylist{},
+		mailer:           &fakeMailer{},
+	}
+	tt.svc = &Service{
+		userDAO:          tt.userDAO,
+		sessionDAO:       tt.sessionDAO,
+		passwordResetDAO: tt.passwordResetDAO,
+		accessTokens:     tt.accessTokens,
+		denylist:         tt.denylist,
+		mailer:           tt.mailer,
+		hasher:           hasher,
+		resetConf: &PasswordResetConfig{
+			LinkURL:  "https://blog.example/reset-password",
+			TokenTTL: 30 * time.Minute,
+		},
+	}
+
+	return tt
+}
+
+func TestRequestPasswordResetOnlyMailsVerifiedEmails(t *testing.T) {
+	tests := []struct {
+		name          string
+		email         string
+		emailVerified bool
+		mailed        bool
+	}{
+		{name: "verified", email: "alice@example.com", emailVerified: true, mailed: true},
+		{name: "unverified", email: "alice@example.com"},
+		{name: "no email"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := &dao.User{ID: primitive.NewObjectID(), Account: "alice", Email: test.email, EmailVerified: test.emailVerified}
+			tt := newPasswordTest(t, user)
+
+			_, err := tt.svc.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{UserAccount: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if mailed := len(tt.mailer.messages) > 0; mailed != test.mailed {
+				t.Fatalf("mailed a reset link: %v, want %v", mailed, test.mailed)
+			}
+			if created := len(tt.passwordResetDAO.resets) > 0; created != test.mailed {
+				t.Fatalf("created a reset token: %v, want %v", created, test.mailed)
+			}
+		})
+	}
+}
+
+func TestConfirmPasswordResetRevokesCredentials(t *testing.T) {
+	user := &dao.User{ID: primitive.NewObjectID(), Account: "alice", Email: "alice@example.com", EmailVerified: true}
+	other := &dao.User{ID: primitive.NewObjectID(), Account: "bob"}
+	tt := newPasswordTest(t, user, other)
+	ctx := context.Background()
+
+	for _, u := range []*dao.User{user, other} {
+		if _, err := tt.sessionDAO.Create(ctx, &dao.Session{UserID: u.ID}); err != nil {
+			t.Fatal(err)
+		}
+		tt.accessTokens.tokens = append(tt.accessTokens.tokens, &authkit.AccessToken{ID: primitive.NewObjectID(), UserID: u.ID})
+	}
+	sessionID := tt.sessionDAO.sessions[0].ID
+
+	if _, err := tt.svc.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{UserAccount: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tt.mailer.messages) != 1 {
+		t.Fatalf("sent %d mails, want 1", len(tt.mailer.messages))
+	}
+	token := resetToken(t, tt.mailer.messages[0].Body)
+
+	if _, err := tt.svc.ConfirmPasswordReset(ctx, &pb.ConfirmPasswordResetRequest{Token: token, NewPassword: "new password"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _, _ := tt.svc.hasher.Verify(tt.userDAO.users[user.ID].Password, "new password"); !ok {
+		t.Error("the password was not replaced")
+	}
+	if !tt.denylist.revoked[sessionID.Hex()] {
+		t.Error("the session of the user was not revoked")
+	}
+	if len(tt.sessionDAO.sessions) != 1 || tt.sessionDAO.sessions[0].UserID != other.ID {
+		t.Errorf("the sessions of the user were not deleted, or the ones of another user were: %v", tt.sessionDAO.sessions)
+	}
+	if len(tt.accessTokens.tokens) != 1 || tt.accessTokens.tokens[0].UserID != other.ID {
+		t.Errorf("the access tokens of the user were not deleted, or the ones of another user were: %v", tt.accessTokens.tokens)
+	}
+
+	// a token can only be used once
+	if _, err := tt.svc.ConfirmPasswordReset(ctx, &pb.ConfirmPasswordResetRequest{Token: token, NewPassword: "again"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("second use of the token returned %v, want %v", err, ErrInvalidResetToken)
+	}
+}
+
+// resetToken finds the token of the reset link in a mail body.
+func resetToken(t *testing.T, body string) string {
+	t.Helper()
+
+	for _, field := range strings.Fields(body) {
+		if strings.HasPrefix(field, "https://blog.example/reset-password?") {
+			u, err := url.Parse(field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return u.Query().Get("token")
+		}
+	}
+
+	t.Fatalf("no reset link in %q", body)
+	return ""
+}
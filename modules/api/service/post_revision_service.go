@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/diffkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Service) ListPostRevisions(ctx context.Context, req *pb.ListPostRevisionsRequest) (*pb.ListPostRevisionsResponse, error) {
+	post, err := s.getAuthoredPost(ctx, req.GetPostId())
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionDAO.ListByPostID(ctx, post.ID, pageLimit(req.GetLimit()), req.GetSkip())
+	if err != nil {
+		return nil, err
+	}
+
+	pbRevisions := make([]*pb.PostRevisionInfo, 0, len(revisions))
+	for _, revision := range revisions {
+		pbRevisions = append(pbRevisions, revision.ToProto())
+	}
+
+	return &pb.ListPostRevisionsResponse{Revisions: pbRevisions}, nil
+}
+
+func (s *Service) GetPostRevision(ctx context.Context, req *pb.GetPostRevisionRequest) (*pb.GetPostRevisionResponse, error) {
+	post, err := s.getAuthoredPost(ctx, req.GetPostId())
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getPostRevision(ctx, post, req.GetRevisionId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetPostRevisionResponse{Revision: revision.ToProto()}, nil
+}
+
+func (s *Service) DiffPostRevisions(ctx context.Context, req *pb.DiffPostRevisionsRequest) (*pb.DiffPostRevisionsResponse, error) {
+	post, err := s.getAuthoredPost(ctx, req.GetPostId())
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.getPostRevision(ctx, post, req.GetFromRevisionId())
+	if err != nil {
+		return nil, err
+	}
+
+	// compare with the current post by default
+	to := dao.NewPostRevision(post)
+	toName := "current"
+	if req.GetToRevisionId() != "" {
+		to, err = s.getPostRevision(ctx, post, req.GetToRevisionId())
+		if err != nil {
+			return nil, err
+		}
+		toName = to.ID.Hex()
+	}
+
+	diff := diffkit.Unified(from.ID.Hex(), toName, from.Content, to.Content, diffkit.DefaultContext)
+
+	return &pb.DiffPostRevisionsResponse{Diff: diff}, nil
+}
+
+func (s *Service) RestorePostRevision(ctx context.Context, req *pb.RestorePostRevisionRequest) (*pb.RestorePostRevisionResponse, error) {
+	post, err := s.getAuthoredPost(ctx, req.GetPostId())
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getPostRevision(ctx, post, req.GetRevisionId())
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &dao.Post{
+		ID:      post.ID,
+		UserID:  post.UserID,
+		Title:   revision.Title,
+		Content: revision.Content,
+		Image:   revision.Image,
+		Tags:    revision.Tags,
+	}
+
+	if err := s.postDAO.UpdateContent(ctx, restored); err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	// the replaced version becomes a revision too, so a restore can be undone
+	if _, err := s.revisionDAO.Create(ctx, dao.NewPostRevision(post)); err != nil {
+		return nil, err
+	}
+
+	return &pb.RestorePostRevisionResponse{}, nil
+}
+
+// getAuthoredPost returns the post if the caller is its author or an editor.
+func (s *Service) getAuthoredPost(ctx context.Context, hexPostID string) (*dao.Post, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := primitive.ObjectIDFromHex(hexPostID)
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	post, err := s.postDAO.Get(ctx, postID)
+	if err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	if post.UserID != userID && !getRoleFromMetadata(ctx).AtLeast(authkit.RoleEditor) {
+		return nil, ErrNotPostAuthor
+	}
+
+	return post, nil
+}
+
+func (s *Service) getPostRevision(ctx context.Context, post *dao.Post, hexRevisionID string) (*dao.PostRevision, error) {
+	revisionID, err := primitive.ObjectIDFromHex(hexRevisionID)
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	revision, err := s.revisionDAO.Get(ctx, revisionID)
+	if err != nil {
+		if errors.Is(err, dao.ErrRevisionNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+
+		return nil, err
+	}
+
+	if revision.PostID != post.ID {
+		return nil, ErrRevisionNotFound
+	}
+
+	return revision, nil
+}
@@ -3,51 +3,85 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/alice890308/blog-server/modules/api/dao"
 	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Service) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.GetPostResponse, error) {
-	postID, err := primitive.ObjectIDFromHex(req.GetPostId())
+	viewerID, _ := getOptionalUserIDFromMetadata(ctx)
+	post, err := s.getVisiblePost(ctx, req.GetPostId(), viewerID)
 	if err != nil {
-		return nil, ErrInvalidObjectID
+		return nil, err
 	}
 
-	post, err := s.postDAO.Get(ctx, postID)
+	pbPosts, err := s.postsToProto(ctx, []*dao.Post{post})
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.userDAO.Get(ctx, post.UserID)
+	return &pb.GetPostResponse{Post: pbPosts[0]}, nil
+}
+
+// getVisiblePost returns the post if the viewer can read it, hidden posts are reported as not found.
+func (s *Service) getVisiblePost(ctx context.Context, hexPostID string, viewerID primitive.ObjectID) (*dao.Post, error) {
+	postID, err := primitive.ObjectIDFromHex(hexPostID)
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	post, err := s.postDAO.Get(ctx, postID)
 	if err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+
 		return nil, err
 	}
 
-	return &pb.GetPostResponse{Post: post.ToProto(user.Name)}, nil
+	if !post.IsVisibleTo(viewerID) {
+		return nil, ErrPostNotFound
+	}
+
+	return post, nil
 }
 
 func (s *Service) ListPost(ctx context.Context, req *pb.ListPostRequest) (*pb.ListPostResponse, error) {
-	posts, err := s.postDAO.List(ctx, req.GetLimit(), req.GetSkip(), req.GetFilter())
+	cursor, err := decodePageToken(req.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 
-	pbPosts := make([]*pb.PostInfo, 0, len(posts))
-	for _, post := range posts {
-		user, err := s.userDAO.Get(ctx, post.UserID)
-		if err != nil {
-			fmt.Println("failed to match user id: " + post.UserID.Hex() + " in post id: " + post.ID.Hex())
-			continue
+	viewerID, _ := getOptionalUserIDFromMetadata(ctx)
+
+	posts, next, err := s.postDAO.List(
+		ctx,
+		pageLimit(req.GetLimit()),
+		req.GetSkip(),
+		req.GetFilter(),
+		req.GetTag(),
+		viewerID,
+		cursor,
+	)
+	if err != nil {
+		// a page token of another listing, such as a search
+		if errors.Is(err, dao.ErrInvalidPageToken) {
+			return nil, ErrInvalidPageToken
 		}
 
-		pbPosts = append(pbPosts, post.ToProto(user.Name))
+		return nil, err
+	}
+
+	pbPosts, err := s.postsToProto(ctx, posts)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.ListPostResponse{Posts: pbPosts}, nil
+	return &pb.ListPostResponse{Posts: pbPosts, NextPageToken: next.Encode()}, nil
 }
 
 func (s *Service) ListPostByUserID(ctx context.Context, req *pb.ListPostByUserIDRequest) (*pb.ListPostByUserIDResponse, error) {
@@ -56,28 +90,63 @@ func (s *Service) ListPostByUserID(ctx context.Context, req *pb.ListPostByUserID
 		return nil, ErrInvalidObjectID
 	}
 
-	posts, err := s.postDAO.ListByUserID(ctx, userID, req.GetLimit(), req.GetSkip())
+	cursor, err := decodePageToken(req.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 
-	pbPosts := make([]*pb.PostInfo, 0, len(posts))
+	viewerID, _ := getOptionalUserIDFromMetadata(ctx)
 
-	var userName string
-	if len(posts) > 0 {
-		user, err := s.userDAO.Get(ctx, posts[0].UserID)
-		if err != nil {
-			return nil, err
+	posts, next, err := s.postDAO.ListByUserID(ctx, userID, pageLimit(req.GetLimit()), req.GetSkip(), viewerID, cursor)
+	if err != nil {
+		// a page token of another listing, such as a search
+		if errors.Is(err, dao.ErrInvalidPageToken) {
+			return nil, ErrInvalidPageToken
 		}
 
-		userName = user.Name
+		return nil, err
 	}
 
-	for _, post := range posts {
-		pbPosts = append(pbPosts, post.ToProto(userName))
+	pbPosts, err := s.postsToProto(ctx, posts)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.ListPostByUserIDResponse{Posts: pbPosts}, nil
+	return &pb.ListPostByUserIDResponse{Posts: pbPosts, NextPageToken: next.Encode()}, nil
+}
+
+func (s *Service) GetFeed(ctx context.Context, req *pb.GetFeedRequest) (*pb.GetFeedResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	followeeIDs, err := s.followDAO.ListFolloweeIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, next, err := s.postDAO.ListByUserIDs(ctx, followeeIDs, pageLimit(req.GetLimit()), cursor)
+	if err != nil {
+		// a page token of another listing, such as a search
+		if errors.Is(err, dao.ErrInvalidPageToken) {
+			return nil, ErrInvalidPageToken
+		}
+
+		return nil, err
+	}
+
+	pbPosts, err := s.postsToProto(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetFeedResponse{Posts: pbPosts, NextPageToken: next.Encode()}, nil
 }
 
 func (s *Service) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.CreatePostResponse, error) {
@@ -86,6 +155,11 @@ func (s *Service) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*p
 		return nil, err
 	}
 
+	status, publishAt, err := resolvePostStatus(req.GetStatus(), req.GetPublishAt(), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &dao.Post{
 		UserID:    userID,
 		Title:     req.GetTitle(),
@@ -94,6 +168,8 @@ func (s *Service) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*p
 		Views:     0,
 		Likes:     0,
 		Tags:      req.GetTags(),
+		Status:    status,
+		PublishAT: publishAt,
 		CreatedAT: time.Now(),
 		UpdatedAT: time.Now(),
 	}
@@ -117,13 +193,37 @@ func (s *Service) UpdatePostContent(ctx context.Context, req *pb.UpdatePostConte
 		return nil, ErrInvalidObjectID
 	}
 
+	prev, err := s.postDAO.Get(ctx, postID)
+	if err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+
+		return nil, err
+	}
+	// editors can edit the posts of everyone
+	if prev.UserID != userID && !getRoleFromMetadata(ctx).AtLeast(authkit.RoleEditor) {
+		return nil, ErrPostNotFound
+	}
+
+	var status dao.PostStatus
+	var publishAt time.Time
+	if req.GetStatus() != pb.PostStatus_POST_STATUS_UNSPECIFIED {
+		status, publishAt, err = resolvePostStatus(req.GetStatus(), req.GetPublishAt(), prev)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	post := &dao.Post{
-		ID:      postID,
-		UserID:  userID,
-		Title:   req.GetTitle(),
-		Content: req.GetContent(),
-		Image:   req.GetImage(),
-		Tags:    req.GetTags(),
+		ID:        postID,
+		UserID:    prev.UserID,
+		Title:     req.GetTitle(),
+		Content:   req.GetContent(),
+		Image:     req.GetImage(),
+		Tags:      req.GetTags(),
+		Status:    status,
+		PublishAT: publishAt,
 	}
 
 	if err := s.postDAO.UpdateContent(ctx, post); err != nil {
@@ -133,16 +233,85 @@ func (s *Service) UpdatePostContent(ctx context.Context, req *pb.UpdatePostConte
 		return nil, err
 	}
 
+	// keep the previous version so that it can be restored, only once it was actually replaced
+	if _, err := s.revisionDAO.Create(ctx, dao.NewPostRevision(prev)); err != nil {
+		return nil, err
+	}
+
 	return &pb.UpdatePostContentResponse{}, nil
 }
 
+// UpdatePostLikes is kept for old clients, it behaves like LikePost.
 func (s *Service) UpdatePostLikes(ctx context.Context, req *pb.UpdatePostLikesRequest) (*pb.UpdatePostLikesResponse, error) {
+	if _, err := s.LikePost(ctx, &pb.LikePostRequest{PostId: req.GetPostId()}); err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdatePostLikesResponse{}, nil
+}
+
+func (s *Service) LikePost(ctx context.Context, req *pb.LikePostRequest) (*pb.LikePostResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// drafts and scheduled posts can not be liked by anyone but their author
+	post, err := s.getVisiblePost(ctx, req.GetPostId(), userID)
+	if err != nil {
+		return nil, err
+	}
+	postID := post.ID
+
+	created, err := s.likeDAO.Create(ctx, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// only count the like once, liking again just reads the current counter
+	delta := 0
+	if created {
+		delta = 1
+	}
+
+	likes, err := s.postDAO.UpdateLikes(ctx, postID, delta)
+	if err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			if created {
+				_, _ = s.likeDAO.Delete(ctx, postID, userID)
+			}
+			return nil, ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	return &pb.LikePostResponse{Likes: uint32(likes)}, nil
+}
+
+func (s *Service) UnlikePost(ctx context.Context, req *pb.UnlikePostRequest) (*pb.UnlikePostResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	postID, err := primitive.ObjectIDFromHex(req.GetPostId())
 	if err != nil {
 		return nil, ErrInvalidObjectID
 	}
 
-	if err := s.postDAO.UpdateLikes(ctx, postID); err != nil {
+	deleted, err := s.likeDAO.Delete(ctx, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := 0
+	if deleted {
+		delta = -1
+	}
+
+	likes, err := s.postDAO.UpdateLikes(ctx, postID, delta)
+	if err != nil {
 		if errors.Is(err, dao.ErrPostNotFound) {
 			return nil, ErrPostNotFound
 		}
@@ -150,7 +319,37 @@ func (s *Service) UpdatePostLikes(ctx context.Context, req *pb.UpdatePostLikesRe
 		return nil, err
 	}
 
-	return &pb.UpdatePostLikesResponse{}, nil
+	return &pb.UnlikePostResponse{Likes: uint32(likes)}, nil
+}
+
+func (s *Service) ListLikers(ctx context.Context, req *pb.ListLikersRequest) (*pb.ListLikersResponse, error) {
+	viewerID, _ := getOptionalUserIDFromMetadata(ctx)
+	post, err := s.getVisiblePost(ctx, req.GetPostId(), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	likes, err := s.likeDAO.ListByPostID(ctx, post.ID, pageLimit(req.GetLimit()), req.GetSkip())
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(likes))
+	for _, like := range likes {
+		userIDs = append(userIDs, like.UserID)
+	}
+
+	users, err := s.getUsersInOrder(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pbUsers := make([]*pb.UserInfo, 0, len(users))
+	for _, user := range users {
+		pbUsers = append(pbUsers, user.ToProto())
+	}
+
+	return &pb.ListLikersResponse{Users: pbUsers}, nil
 }
 
 func (s *Service) UpdatePostViews(ctx context.Context, req *pb.UpdatePostViewsRequest) (*pb.UpdatePostViewsResponse, error) {
@@ -159,7 +358,8 @@ func (s *Service) UpdatePostViews(ctx context.Context, req *pb.UpdatePostViewsRe
 		return nil, ErrInvalidObjectID
 	}
 
-	if err := s.postDAO.UpdateViews(ctx, postID); err != nil {
+	post, err := s.postDAO.Get(ctx, postID)
+	if err != nil {
 		if errors.Is(err, dao.ErrPostNotFound) {
 			return nil, ErrPostNotFound
 		}
@@ -167,6 +367,16 @@ func (s *Service) UpdatePostViews(ctx context.Context, req *pb.UpdatePostViewsRe
 		return nil, err
 	}
 
+	// only published posts are counted
+	if !post.IsPublished() {
+		return &pb.UpdatePostViewsResponse{}, nil
+	}
+
+	// views are deduplicated per viewer and flushed to the post in the background
+	if _, err := s.viewDAO.Record(ctx, postID, getViewerFromMetadata(ctx, s.loginConf.TrustedProxies)); err != nil {
+		return nil, err
+	}
+
 	return &pb.UpdatePostViewsResponse{}, nil
 }
 
@@ -181,13 +391,127 @@ func (s *Service) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*p
 		return nil, ErrInvalidObjectID
 	}
 
-	if err := s.postDAO.Delete(ctx, postID, userID); err != nil {
+	post, err := s.postDAO.Get(ctx, postID)
+	if err != nil {
 		if errors.Is(err, dao.ErrPostNotFound) {
 			return nil, ErrPostNotFound
 		}
 
 		return nil, err
 	}
+	// editors can delete the posts of everyone
+	if post.UserID != userID && !getRoleFromMetadata(ctx).AtLeast(authkit.RoleEditor) {
+		return nil, ErrPostNotFound
+	}
+
+	if err := s.postDAO.Delete(ctx, postID, post.UserID); err != nil {
+		if errors.Is(err, dao.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+
+		return nil, err
+	}
+
+	if err := s.commentDAO.DeleteByPostID(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	if err := s.likeDAO.DeleteByPostID(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	if err := s.revisionDAO.DeleteByPostID(ctx, postID); err != nil {
+		return nil, err
+	}
 
 	return &pb.DeletePostResponse{}, nil
 }
+
+// postsToProto converts the posts with their author names resolved in one round trip.
+func (s *Service) postsToProto(ctx context.Context, posts []*dao.Post) ([]*pb.PostInfo, error) {
+	userIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		userIDs = append(userIDs, post.UserID)
+	}
+
+	userNames, err := s.getUserNames(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pbPosts := make([]*pb.PostInfo, 0, len(posts))
+	for _, post := range posts {
+		pbPosts = append(pbPosts, post.ToProto(userNames[post.UserID]))
+	}
+
+	if err := s.markLikedByMe(ctx, pbPosts); err != nil {
+		return nil, err
+	}
+
+	return pbPosts, nil
+}
+
+// markLikedByMe fills the liked_by_me flag of the posts when the caller is authenticated.
+func (s *Service) markLikedByMe(ctx context.Context, posts []*pb.PostInfo) error {
+	userID, ok := getOptionalUserIDFromMetadata(ctx)
+	if !ok || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		postID, err := primitive.ObjectIDFromHex(post.GetPostId())
+		if err != nil {
+			return ErrInvalidObjectID
+		}
+
+		postIDs = append(postIDs, postID)
+	}
+
+	liked, err := s.likeDAO.ListLikedPostIDs(ctx, userID, postIDs)
+	if err != nil {
+		return err
+	}
+
+	likedSet := make(map[string]bool, len(liked))
+	for _, postID := range liked {
+		likedSet[postID.Hex()] = true
+	}
+
+	for _, post := range posts {
+		post.LikedByMe = likedSet[post.GetPostId()]
+	}
+
+	return nil
+}
+
+// resolvePostStatus validates the requested status of a post, prev is the stored post when updating.
+// Scheduled posts whose publish time has already passed are published right away.
+func resolvePostStatus(status pb.PostStatus, publishAt *timestamppb.Timestamp, prev *dao.Post) (dao.PostStatus, time.Time, error) {
+	now := time.Now()
+
+	switch status {
+	case pb.PostStatus_POST_STATUS_UNSPECIFIED, pb.PostStatus_POST_STATUS_PUBLISHED:
+		// keep the original publish time when a published post is updated
+		if prev != nil && prev.IsPublished() && !prev.PublishAT.IsZero() {
+			return dao.PostStatusPublished, prev.PublishAT, nil
+		}
+		return dao.PostStatusPublished, now, nil
+	case pb.PostStatus_POST_STATUS_SCHEDULED:
+		if publishAt == nil || !publishAt.IsValid() {
+			return "", time.Time{}, ErrPublishAtRequired
+		}
+		if t := publishAt.AsTime(); t.After(now) {
+			return dao.PostStatusScheduled, t, nil
+		}
+		return dao.PostStatusPublished, now, nil
+	case pb.PostStatus_POST_STATUS_DRAFT, pb.PostStatus_POST_STATUS_ARCHIVED:
+		var t time.Time
+		if prev != nil {
+			t = prev.PublishAT
+		}
+		return dao.PostStatusFromProto(status), t, nil
+	default:
+		return "", time.Time{}, ErrInvalidPostStatus
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/metadata"
+)
+
+// newAuthorsFixture returns a page of posts by a few authors, the last author no longer exists.
+func newAuthorsFixture(posts, authors int) (*fakeUserDAO, []*dao.Post) {
+	users := make([]*dao.User, 0, authors)
+	for i := 0; i < authors; i++ {
+		users = append(users, &dao.User{ID: primitive.NewObjectID(), Name: "author"})
+	}
+	deletedID := primitive.NewObjectID()
+
+	page := make([]*dao.Post, 0, posts)
+	for i := 0; i < posts; i++ {
+		userID := deletedID
+		if i%(authors+1) < authors {
+			userID = users[i%(authors+1)].ID
+		}
+		page = append(page, &dao.Post{ID: primitive.NewObjectID(), UserID: userID, CreatedAT: time.Now()})
+	}
+
+	return newFakeUserDAO(users...), page
+}
+
+func TestPostsToProto(t *testing.T) {
+	userDAO, posts := newAuthorsFixture(20, 4)
+	s := &Service{userDAO: userDAO}
+
+	pbPosts, err := s.postsToProto(context.Background(), posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if userDAO.calls != 1 {
+		t.Errorf("resolved the authors in %d round trips, want 1", userDAO.calls)
+	}
+	if len(pbPosts) != len(posts) {
+		t.Fatalf("got %d posts, want %d with the posts of deleted authors kept", len(pbPosts), len(posts))
+	}
+	for i, post := range pbPosts {
+		want := "author"
+		if _, ok := userDAO.users[posts[i].UserID]; !ok {
+			want = deletedUserName
+		}
+		if post.GetPostId() != posts[i].ID.Hex() || post.GetUserName() != want {
+			t.Errorf("post %d is %s by %q, want %s by %q", i, post.GetPostId(), post.GetUserName(), posts[i].ID.Hex(), want)
+		}
+	}
+}
+
+// BenchmarkPostAuthors compares resolving the authors of a page of posts one lookup per post, as ListPost used
+// to, with the batched lookup, against a user DAO with the latency of a database round trip.
+func BenchmarkPostAuthors(b *testing.B) {
+	const latency = 100 * time.Microsecond
+	ctx := context.Background()
+
+	b.Run("per_item", func(b *testing.B) {
+		userDAO, posts := newAuthorsFixture(20, 4)
+		userDAO.latency = latency
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			pbPosts := make([]*pb.PostInfo, 0, len(posts))
+			for _, post := range posts {
+				user, err := userDAO.Get(ctx, post.UserID)
+				if err != nil {
+					continue
+				}
+				pbPosts = append(pbPosts, post.ToProto(user.Name))
+			}
+		}
+
+		b.ReportMetric(float64(userDAO.calls)/float64(b.N), "lookups/op")
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		userDAO, posts := newAuthorsFixture(20, 4)
+		userDAO.latency = latency
+		s := &Service{userDAO: userDAO}
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := s.postsToProto(ctx, posts); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.ReportMetric(float64(userDAO.calls)/float64(b.N), "lookups/op")
+	})
+}
+
+func TestUpdatePostContentKeepsRevisionOfUpdatedPosts(t *testing.T) {
+	author := primitive.NewObjectID()
+	post := &dao.Post{ID: primitive.NewObjectID(), UserID: author, Title: "title", Content: "first", Status: dao.PostStatusPublished}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", author.Hex(), "role", string(authkit.RoleAuthor)))
+	req := &pb.UpdatePostContentRequest{PostId: post.ID.Hex(), Title: "title", Content: "second"}
+
+	t.Run("updated", func(t *testing.T) {
+		postDAO, revisionDAO := newFakePostDAO(post), &fakeRevisionDAO{}
+		s := &Service{postDAO: postDAO, revisionDAO: revisionDAO}
+
+		if _, err := s.UpdatePostContent(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+		if len(revisionDAO.revisions) != 1 || revisionDAO.revisions[0].Content != "first" {
+			t.Fatalf("kept revisions %v, want the previous content", revisionDAO.revisions)
+		}
+		if postDAO.posts[post.ID].Content != "second" {
+			t.Errorf("content is %q, want %q", postDAO.posts[post.ID].Content, "second")
+		}
+	})
+
+	t.Run("update failed", func(t *testing.T) {
+		postDAO, revisionDAO := newFakePostDAO(post), &fakeRevisionDAO{}
+		// the post is deleted between the read and the update
+		postDAO.updateErr = dao.ErrPostNotFound
+		s := &Service{postDAO: postDAO, revisionDAO: revisionDAO}
+
+		if _, err := s.UpdatePostContent(ctx, req); !errors.Is(err, ErrPostNotFound) {
+			t.Fatalf("UpdatePostContent returned %v, want %v", err, ErrPostNotFound)
+		}
+		if len(revisionDAO.revisions) != 0 {
+			t.Errorf("kept %d revisions of a post that was not updated", len(revisionDAO.revisions))
+		}
+	})
+}
+
+func TestLikesOfHiddenPosts(t *testing.T) {
+	author, reader := primitive.NewObjectID(), primitive.NewObjectID()
+	draft := &dao.Post{ID: primitive.NewObjectID(), UserID: author, Status: dao.PostStatusDraft}
+	published := &dao.Post{ID: primitive.NewObjectID(), UserID: author, Status: dao.PostStatusPublished}
+	asUser := func(userID primitive.ObjectID) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", userID.Hex(), "role", string(authkit.RoleReader)))
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		post    *dao.Post
+		visible bool
+	}{
+		{name: "published", ctx: asUser(reader), post: published, visible: true},
+		{name: "draft of another user", ctx: asUser(reader), post: draft},
+		{name: "draft of the caller", ctx: asUser(author), post: draft, visible: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			likeDAO := &fakeLikeDAO{}
+			s := &Service{postDAO: newFakePostDAO(&dao.Post{ID: test.post.ID, UserID: test.post.UserID, Status: test.post.Status}), likeDAO: likeDAO, userDAO: newFakeUserDAO()}
+
+			_, likeErr := s.LikePost(test.ctx, &pb.LikePostRequest{PostId: test.post.ID.Hex()})
+			_, listErr := s.ListLikers(test.ctx, &pb.ListLikersRequest{PostId: test.post.ID.Hex()})
+
+			if test.visible {
+				if likeErr != nil || listErr != nil {
+					t.Fatalf("LikePost returned %v and ListLikers %v, want no error", likeErr, listErr)
+				}
+				return
+			}
+			if !errors.Is(likeErr, ErrPostNotFound) || !errors.Is(listErr, ErrPostNotFound) {
+				t.Errorf("LikePost returned %v and ListLikers %v, want %v", likeErr, listErr, ErrPostNotFound)
+			}
+			if likeDAO.calls != 0 {
+				t.Errorf("touched the likes of a hidden post %d times", likeDAO.calls)
+			}
+		})
+	}
+
+	t.Run("anonymous likers of a draft", func(t *testing.T) {
+		likeDAO := &fakeLikeDAO{}
+		s := &Service{postDAO: newFakePostDAO(draft), likeDAO: likeDAO}
+
+		if _, err := s.ListLikers(context.Background(), &pb.ListLikersRequest{PostId: draft.ID.Hex()}); !errors.Is(err, ErrPostNotFound) {
+			t.Errorf("ListLikers returned %v, want %v", err, ErrPostNotFound)
+		}
+		if likeDAO.calls != 0 {
+			t.Errorf("touched the likes of a hidden post %d times", likeDAO.calls)
+		}
+	})
+}
+
+func TestUpdatePostViewsDedupesSpoofedForwardedFor(t *testing.T) {
+	post := &dao.Post{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: dao.PostStatusPublished}
+	viewDAO := &fakeViewDAO{}
+	// one proxy in front of the gateway, which appends the address it sees
+	s := &Service{postDAO: newFakePostDAO(post), viewDAO: viewDAO, loginConf: &LoginLimitConfig{TrustedProxies: 1}}
+
+	view := func(forwardedFor string) {
+		t.Helper()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", forwardedFor, "user-agent", "browser"))
+		if _, err := s.UpdatePostViews(ctx, &pb.UpdatePostViewsRequest{PostId: post.ID.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the client sends any address it likes before the ones the proxies append
+	view("1.1.1.1, 203.0.113.7, 10.0.0.2")
+	view("2.2.2.2, 203.0.113.7, 10.0.0.2")
+	view("203.0.113.7, 10.0.0.2")
+	if len(viewDAO.viewers) != 1 {
+		t.Fatalf("counted %d viewers for one client, want 1", len(viewDAO.viewers))
+	}
+
+	view("198.51.100.4, 10.0.0.2")
+	if len(viewDAO.viewers) != 2 {
+		t.Errorf("counted %d viewers for two clients, want 2", len(viewDAO.viewers))
+	}
+}
@@ -2,30 +2,154 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
 
 	"github.com/alice890308/blog-server/modules/api/dao"
 	"github.com/alice890308/blog-server/modules/api/pb"
 	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/mailkit"
+	"github.com/alice890308/blog-server/pkg/passwordkit"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
+// deletedUserName is shown as the author of posts and comments whose user no longer exists.
+const deletedUserName = "[deleted]"
+
 type Service struct {
 	pb.UnimplementedPostServer
 	pb.UnimplementedUserServer
 	pb.UnimplementedSessionServer
+	pb.UnimplementedCommentServer
+
+	userDAO          dao.UserDAO
+	postDAO          dao.PostDAO
+	commentDAO       dao.CommentDAO
+	likeDAO          dao.LikeDAO
+	viewDAO          dao.ViewDAO
+	revisionDAO      dao.RevisionDAO
+	followDAO        dao.FollowDAO
+	sessionDAO       dao.SessionDAO
+	passwordResetDAO dao.PasswordResetDAO
+	identityDAO      dao.IdentityDAO
+	jwtManager       authkit.JWT
+	denylist         authkit.Denylist
+	mailer           mailkit.Mailer
+	hasher           passwordkit.Hasher
+	loginAttemptDAO  dao.LoginAttemptDAO
+	accessTokens     authkit.AccessTokenStore
+	oidc             *authkit.OIDCProvider
+	resetConf        *PasswordResetConfig
+	emailConf        *EmailVerificationConfig
+	loginConf        *LoginLimitConfig
+	totpConf         *TOTPConfig
+	logger           *logkit.Logger
 
-	userDAO    dao.UserDAO
-	postDAO    dao.PostDAO
-	jwtManager authkit.JWT
+	dummyHash     string
+	dummyHashOnce sync.Once
 }
 
-func NewService(postDAO dao.PostDAO, userDAO dao.UserDAO, jwtManager authkit.JWT) *Service {
+func NewService(
+	postDAO dao.PostDAO,
+	userDAO dao.UserDAO,
+	commentDAO dao.CommentDAO,
+	likeDAO dao.LikeDAO,
+	viewDAO dao.ViewDAO,
+	revisionDAO dao.RevisionDAO,
+	followDAO dao.FollowDAO,
+	sessionDAO dao.SessionDAO,
+	passwordResetDAO dao.PasswordResetDAO,
+	identityDAO dao.IdentityDAO,
+	jwtManager authkit.JWT,
+	denylist authkit.Denylist,
+	mailer mailkit.Mailer,
+	hasher passwordkit.Hasher,
+	loginAttemptDAO dao.LoginAttemptDAO,
+	accessTokens authkit.AccessTokenStore,
+	oidc *authkit.OIDCProvider,
+	resetConf *PasswordResetConfig,
+	emailConf *EmailVerificationConfig,
+	loginConf *LoginLimitConfig,
+	totpConf *TOTPConfig,
+	logger *logkit.Logger,
+) *Service {
 	return &Service{
-		userDAO:    userDAO,
-		postDAO:    postDAO,
-		jwtManager: jwtManager,
+		userDAO:          userDAO,
+		postDAO:          postDAO,
+		commentDAO:       commentDAO,
+		likeDAO:          likeDAO,
+		viewDAO:          viewDAO,
+		revisionDAO:      revisionDAO,
+		followDAO:        followDAO,
+		sessionDAO:       sessionDAO,
+		passwordResetDAO: passwordResetDAO,
+		identityDAO:      identityDAO,
+		jwtManager:       jwtManager,
+		denylist:         denylist,
+		mailer:           mailer,
+		hasher:           hasher,
+		loginAttemptDAO:  loginAttemptDAO,
+		accessTokens:     accessTokens,
+		oidc:             oidc,
+		resetConf:        resetConf,
+		emailConf:        emailConf,
+		loginConf:        loginConf,
+		totpConf:         totpConf,
+		logger:           logger,
+	}
+}
+
+// getUserNames resolves the names of the users in one round trip, users that no longer exist get deletedUserName.
+func (s *Service) getUserNames(ctx context.Context, userIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	userNames := make(map[primitive.ObjectID]string, len(userIDs))
+
+	ids := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := userNames[id]; !ok {
+			userNames[id] = deletedUserName
+			ids = append(ids, id)
+		}
+	}
+
+	users, err := s.userDAO.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		userNames[user.ID] = user.Name
 	}
+
+	return userNames, nil
+}
+
+// getUsersInOrder resolves the users in one round trip and keeps the order of userIDs,
+// users that no longer exist are skipped.
+func (s *Service) getUsersInOrder(ctx context.Context, userIDs []primitive.ObjectID) ([]*dao.User, error) {
+	users, err := s.userDAO.GetMany(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByID := make(map[primitive.ObjectID]*dao.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	ordered := make([]*dao.User, 0, len(userIDs))
+	for _, id := range userIDs {
+		if user, ok := usersByID[id]; ok {
+			ordered = append(ordered, user)
+		}
+	}
+
+	return ordered, nil
 }
 
 func getUserIDFromMetadata(ctx context.Context) (primitive.ObjectID, error) {
@@ -41,3 +165,95 @@ func getUserIDFromMetadata(ctx context.Context) (primitive.ObjectID, error) {
 
 	return userID, nil
 }
+
+// getRoleFromMetadata returns the role of the caller, it is empty for anonymous callers.
+func getRoleFromMetadata(ctx context.Context) authkit.Role {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["role"]) == 0 {
+		return ""
+	}
+
+	return authkit.Role(md["role"][0])
+}
+
+// getOptionalUserIDFromMetadata returns the caller of a public API if a valid token was provided,
+// the second return value is false for anonymous callers.
+func getOptionalUserIDFromMetadata(ctx context.Context) (primitive.ObjectID, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["user_id"]) == 0 {
+		return primitive.NilObjectID, false
+	}
+
+	userID, err := primitive.ObjectIDFromHex(md["user_id"][0])
+	if err != nil {
+		return primitive.NilObjectID, false
+	}
+
+	return userID, true
+}
+
+// getViewerFromMetadata identifies the viewer of a post, authenticated callers are identified by their user ID,
+// anonymous ones by a hash of their client address, as seen by the outermost trusted proxy, and user agent.
+func getViewerFromMetadata(ctx context.Context, trustedProxies int) string {
+	if userID, ok := getOptionalUserIDFromMetadata(ctx); ok {
+		return "user:" + userID.Hex()
+	}
+
+	var userAgent string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md["grpcgateway-user-agent"]; len(values) > 0 {
+			userAgent = values[0]
+		} else if values := md["user-agent"]; len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	addr := getClientAddrFromMetadata(ctx, trustedProxies)
+	fingerprint := sha256.Sum256([]byte(addr + "|" + userAgent))
+
+	return "client:" + hex.EncodeToString(fingerprint[:])
+}
+
+// getClientAddrFromMetadata returns the address of the client as seen by the outermost of trustedProxies proxies
+// in front of the gateway, the addresses before it in x-forwarded-for are sent by the client and can not be trusted.
+func getClientAddrFromMetadata(ctx context.Context, trustedProxies int) string {
+	addrs := getClientAddrsFromMetadata(ctx)
+	if len(addrs) == 0 {
+		return ""
+	}
+
+	i := len(addrs) - 1 - trustedProxies
+	if i < 0 {
+		i = 0
+	}
+
+	return addrs[i]
+}
+
+// getClientAddrsFromMetadata returns the addresses of x-forwarded-for, to which the gateway appends the remote address,
+// or the peer address for requests that did not go through the gateway.
+func getClientAddrsFromMetadata(ctx context.Context) []string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md["x-forwarded-for"]) > 0 {
+		var addrs []string
+		for _, value := range md["x-forwarded-for"] {
+			for _, addr := range strings.Split(value, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+		if len(addrs) > 0 {
+			return addrs
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+		return []string{addr}
+	}
+
+	return nil
+}
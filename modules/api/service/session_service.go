@@ -2,36 +2,272 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"time"
 
 	"github.com/alice890308/blog-server/modules/api/dao"
 	"github.com/alice890308/blog-server/modules/api/pb"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/metadata"
 )
 
+// tokenSize is the number of random bytes of refresh and password reset tokens.
+const tokenSize = 32
+
 func (s *Service) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
 	return &pb.HealthResponse{Status: "ok"}, nil
 }
 
 func (s *Service) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	user, err := s.userDAO.GetByUserAccount(ctx, req.GetUserAccount())
+	account := req.GetUserAccount()
+	addr := getClientAddrFromMetadata(ctx, s.loginConf.TrustedProxies)
+
+	if err := s.checkLoginLock(ctx, account, addr); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDAO.GetByUserAccount(ctx, account)
+	if err != nil && !errors.Is(err, dao.ErrUserNotFound) {
+		return nil, err
+	}
+
+	var ok, needsRehash bool
+	// users created by an OpenID Connect login have no password until they set one
+	if user != nil && user.Password != "" {
+		ok, needsRehash, err = s.hasher.Verify(user.Password, req.GetUserPassword())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// spend the time of a verification, so that unknown accounts can not be told apart by the response time
+		_, _, _ = s.hasher.Verify(s.getDummyHash(), req.GetUserPassword())
+	}
+
+	if !ok {
+		if err := s.recordLoginFailure(ctx, account, addr); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	// upgrade hashes made with a weaker cost or another algorithm while the password is at hand,
+	// a failure only delays the upgrade to the next login
+	if needsRehash {
+		if hashedPWD, err := s.hasher.Hash(req.GetUserPassword()); err == nil {
+			_ = s.userDAO.UpdatePassword(ctx, user.ID, hashedPWD)
+		}
+	}
+
+	// the failures are kept until the second factor is verified too, so codes can not be guessed
+	// by logging in again in between
+	if user.TOTPEnabled {
+		return s.newChallengeResponse(user)
+	}
+
+	if err := s.loginAttemptDAO.Reset(ctx, loginAccountKey(account)); err != nil {
+		return nil, err
+	}
+
+	return s.newSessionResponse(ctx, user)
+}
+
+// newChallengeResponse answers a login of a user with two-factor authentication, which VerifyLoginChallenge completes.
+func (s *Service) newChallengeResponse(user *dao.User) (*pb.LoginResponse, error) {
+	challengeToken, err := s.jwtManager.GenerateChallenge(user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LoginResponse{
+		UserId:            user.ID.Hex(),
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+	}, nil
+}
+
+func (s *Service) newSessionResponse(ctx context.Context, user *dao.User) (*pb.LoginResponse, error) {
+	token, refreshToken, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.LoginResponse{
+		Token:        token,
+		UserId:       user.ID.Hex(),
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtManager.TokenDuration().Seconds()),
+	}, nil
+}
+
+// startSession creates a session of the user and returns its access token and refresh token.
+func (s *Service) startSession(ctx context.Context, user *dao.User) (string, string, error) {
+	refreshToken, tokenHash, err := newToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	session := &dao.Session{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAT: now.Add(s.jwtManager.RefreshTokenDuration()),
+		CreatedAT: now,
+		UpdatedAT: now,
+	}
+	if _, err := s.sessionDAO.Create(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	token, err := s.jwtManager.Generate(user.ID.Hex(), session.ID.Hex(), user.GetRole())
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token,
+// the old refresh token can not be used again.
+func (s *Service) RefreshSession(ctx context.Context, req *pb.RefreshSessionRequest) (*pb.RefreshSessionResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	refreshToken, newTokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenHash := hashToken(req.GetRefreshToken())
+	session, err := s.sessionDAO.Rotate(ctx, tokenHash, newTokenHash, time.Now().Add(s.jwtManager.RefreshTokenDuration()))
+	if err != nil {
+		if !errors.Is(err, dao.ErrSessionNotFound) {
+			return nil, err
+		}
+
+		// a refresh token that was already rotated is being replayed, end the session for both holders
+		if reused, err := s.sessionDAO.GetByPreviousTokenHash(ctx, tokenHash); err == nil {
+			if err := s.revokeSessions(ctx, reused.ID); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, dao.ErrSessionNotFound) {
+			return nil, err
+		}
+
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// the role is read again, it may have changed since the login
+	user, err := s.userDAO.Get(ctx, session.UserID)
 	if err != nil {
 		if errors.Is(err, dao.ErrUserNotFound) {
-			return nil, ErrUserNotFound
+			return nil, ErrInvalidRefreshToken
 		}
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.GetUserPassword()))
+	userID := user.ID.Hex()
+	token, err := s.jwtManager.Generate(userID, session.ID.Hex(), user.GetRole())
 	if err != nil {
-		return nil, ErrWrongPWD
+		return nil, err
 	}
 
-	userID := user.ID.Hex()
-	token, err := s.jwtManager.Generate(userID)
+	return &pb.RefreshSessionResponse{
+		Token:        token,
+		UserId:       userID,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtManager.TokenDuration().Seconds()),
+	}, nil
+}
+
+func (s *Service) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md["session_id"]) == 0 || len(md["token_id"]) == 0 {
+		return nil, ErrMetadataNotProivided
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(md["session_id"][0])
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	if err := s.denylist.Revoke(ctx, md["token_id"][0]); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeSessions(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *Service) LogoutAllDevices(ctx context.Context, req *pb.LogoutAllDevicesRequest) (*pb.LogoutAllDevicesResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.LoginResponse{Token: token, UserId: userID}, nil
+	if err := s.revokeUserSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return &pb.LogoutAllDevicesResponse{}, nil
+}
+
+// revokeUserSessions ends every session of the user, their access tokens stop working right away.
+func (s *Service) revokeUserSessions(ctx context.Context, userID primitive.ObjectID) error {
+	ids, err := s.sessionDAO.ListIDsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revokeSessions(ctx, ids...); err != nil {
+		return err
+	}
+
+	// sessions created in the meantime have not been denylisted, but they are deleted anyway
+	return s.sessionDAO.DeleteByUserID(ctx, userID)
+}
+
+// revokeSessions denylists the sessions before deleting them, so a failure never leaves live access tokens
+// of a deleted session behind.
+func (s *Service) revokeSessions(ctx context.Context, ids ...primitive.ObjectID) error {
+	sessionIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		sessionIDs = append(sessionIDs, id.Hex())
+	}
+
+	if err := s.denylist.Revoke(ctx, sessionIDs...); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := s.sessionDAO.Delete(ctx, id); err != nil && !errors.Is(err, dao.ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newToken returns a random opaque token and its hash, only the hash is stored.
+func newToken() (string, string, error) {
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/metadata"
+)
+
+type sessionTest struct {
+	svc        *Service
+	user       *dao.User
+	sessionDAO *fakeSessionDAO
+	denylist   *fakeDenylist
+	// auth checks access tokens the way the interceptor does, against the same denylist
+	auth *authkit.AuthService
+}
+
+func newSessionTest(t *testing.T) *sessionTest {
+	t.Helper()
+
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	jwtManager := authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+		SecretKey:            "secret",
+		Issuer:               "blog-server",
+		Audience:             "blog-server",
+		TokenDuration:        15 * time.Minute,
+		RefreshTokenDuration: time.Hour,
+	})
+
+	tt := &sessionTest{
+		user:       &dao.User{ID: primitive.NewObjectID(), Account: "alice", Role: authkit.RoleReader},
+		sessionDAO: &fakeSessionDAO{},
+		denylist:   &fakeDenylist{},
+	}
+	tt.svc = &Service{
+		userDAO:    newFakeUserDAO(tt.user),
+		sessionDAO: tt.sessionDAO,
+		denylist:   tt.denylist,
+		jwtManager: jwtManager,
+		logger:     logger,
+	}
+	tt.auth = authkit.NewAuthService(jwtManager, tt.denylist, nil, nil)
+
+	return tt
+}
+
+// login starts a session and returns its access token and refresh token.
+func (tt *sessionTest) login(t *testing.T) (string, string) {
+	t.Helper()
+
+	token, refreshToken, err := tt.svc.startSession(context.Background(), tt.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token, refreshToken
+}
+
+func (tt *sessionTest) refresh(refreshToken string) (*pb.RefreshSessionResponse, error) {
+	return tt.svc.RefreshSession(context.Background(), &pb.RefreshSessionRequest{RefreshToken: refreshToken})
+}
+
+func (tt *sessionTest) checkToken(t *testing.T, token string, want error) {
+	t.Helper()
+
+	if _, err := tt.auth.Authorize(context.Background(), "Bearer "+token, authkit.Rule{Role: authkit.RoleReader}); err != want {
+		t.Errorf("the access token is checked as %v, want %v", err, want)
+	}
+}
+
+func TestRefreshSessionRotates(t *testing.T) {
+	tt := newSessionTest(t)
+	_, refreshToken := tt.login(t)
+
+	resp, err := tt.refresh(refreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetRefreshToken() == "" || resp.GetRefreshToken() == refreshToken {
+		t.Fatalf("the refresh token was not rotated: %q", resp.GetRefreshToken())
+	}
+	tt.checkToken(t, resp.GetToken(), nil)
+
+	// the new refresh token keeps working
+	if _, err := tt.refresh(resp.GetRefreshToken()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshSessionDetectsReuse(t *testing.T) {
+	tt := newSessionTest(t)
+	_, stolen := tt.login(t)
+	_, otherRefreshToken := tt.login(t)
+
+	rotated, err := tt.refresh(stolen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionID := tt.sessionDAO.sessions[0].ID
+
+	// the rotated away token is replayed by whoever stole it
+	if _, err := tt.refresh(stolen); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("replaying a rotated refresh token returned %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	if !tt.denylist.revoked[sessionID.Hex()] {
+		t.Error("the session of the replayed token was not revoked")
+	}
+	tt.checkToken(t, rotated.GetToken(), authkit.TokenRevoked)
+	if _, err := tt.refresh(rotated.GetRefreshToken()); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("the latest refresh token of a replayed session returned %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// the other sessions of the user are not affected
+	if _, err := tt.refresh(otherRefreshToken); err != nil {
+		t.Errorf("another session was ended: %v", err)
+	}
+}
+
+func TestRefreshSessionRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, tt *sessionTest) string
+	}{
+		{name: "empty", setup: func(t *testing.T, tt *sessionTest) string { return "" }},
+		{name: "unknown", setup: func(t *testing.T, tt *sessionTest) string { return "unknown" }},
+		{
+			name: "expired",
+			setup: func(t *testing.T, tt *sessionTest) string {
+				_, refreshToken := tt.login(t)
+				tt.sessionDAO.sessions[0].ExpiresAT = time.Now().Add(-time.Second)
+				return refreshToken
+			},
+		},
+		{
+			name: "deleted user",
+			setup: func(t *testing.T, tt *sessionTest) string {
+				_, refreshToken := tt.login(t)
+				delete(tt.svc.userDAO.(*fakeUserDAO).users, tt.user.ID)
+				return refreshToken
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tt := newSessionTest(t)
+
+			if _, err := tt.refresh(test.setup(t, tt)); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("RefreshSession returned %v, want %v", err, ErrInvalidRefreshToken)
+			}
+			if len(tt.denylist.revoked) != 0 {
+				t.Errorf("revoked %v without any reuse", tt.denylist.revoked)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesTokenAndSession(t *testing.T) {
+	tt := newSessionTest(t)
+	token, refreshToken := tt.login(t)
+	otherToken, otherRefreshToken := tt.login(t)
+
+	payload, err := tt.auth.Authorize(context.Background(), "Bearer "+token, authkit.Rule{Role: authkit.RoleReader})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the interceptor sets the session and token of the caller
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"user_id", payload.UserID,
+		"session_id", payload.SessionID,
+		"token_id", payload.Id,
+	))
+
+	if _, err := tt.svc.Logout(ctx, &pb.LogoutRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tt.denylist.revoked[payload.Id] || !tt.denylist.revoked[payload.SessionID] {
+		t.Errorf("revoked %v, want the token %s and the session %s", tt.denylist.revoked, payload.Id, payload.SessionID)
+	}
+	tt.checkToken(t, token, authkit.TokenRevoked)
+	if _, err := tt.refresh(refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing a logged out session returned %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// only the session logged out of is ended
+	tt.checkToken(t, otherToken, nil)
+	if _, err := tt.refresh(otherRefreshToken); err != nil {
+		t.Errorf("another session was ended: %v", err)
+	}
+}
+
+func TestLogoutAllDevices(t *testing.T) {
+	tt := newSessionTest(t)
+	token, _ := tt.login(t)
+	otherToken, otherRefreshToken := tt.login(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", tt.user.ID.Hex()))
+	if _, err := tt.svc.LogoutAllDevices(ctx, &pb.LogoutAllDevicesRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tt.checkToken(t, token, authkit.TokenRevoked)
+	tt.checkToken(t, otherToken, authkit.TokenRevoked)
+	if _, err := tt.refresh(otherRefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing after logging out of all devices returned %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if len(tt.sessionDAO.sessions) != 0 {
+		t.Errorf("%d sessions are left", len(tt.sessionDAO.sessions))
+	}
+}
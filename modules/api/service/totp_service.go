@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/otpkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the number of random bytes of a recovery code, 10 base32 characters
+	recoveryCodeSize = 6
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TOTPConfig struct {
+	Issuer string `long:"issuer" env:"ISSUER" description:"issuer shown by authenticator apps" default:"blog-server"`
+	Skew   int    `long:"skew" env:"SKEW" description:"number of 30s time steps a code is accepted before and after its own" default:"1"`
+}
+
+// EnrollTOTP starts the enrollment of two-factor authentication, it only takes effect once ActivateTOTP verifies a code.
+func (s *Service) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	user, err := s.getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := otpkit.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userDAO.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			// enabled concurrently
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &pb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUri: otpkit.URI(s.totpConf.Issuer, user.Account, secret),
+	}, nil
+}
+
+func (s *Service) ActivateTOTP(ctx context.Context, req *pb.ActivateTOTPRequest) (*pb.ActivateTOTPResponse, error) {
+	user, err := s.getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	counter, ok := otpkit.Validate(user.TOTPSecret, strings.TrimSpace(req.GetCode()), time.Now(), s.totpConf.Skew)
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userDAO.EnableTOTP(ctx, user.ID, counter, hashes); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &pb.ActivateTOTPResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *Service) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	user, err := s.getCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// users created by an OpenID Connect login have no password to check until they reset it
+	if user.Password == "" {
+		return nil, ErrWrongPWD
+	}
+	if ok, _, err := s.hasher.Verify(user.Password, req.GetPassword()); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrWrongPWD
+	}
+
+	if err := s.userDAO.DisableTOTP(ctx, user.ID); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &pb.DisableTOTPResponse{}, nil
+}
+
+// VerifyLoginChallenge finishes a login of a user with two-factor authentication.
+func (s *Service) VerifyLoginChallenge(ctx context.Context, req *pb.VerifyLoginChallengeRequest) (*pb.VerifyLoginChallengeResponse, error) {
+	payload, err := s.jwtManager.VerifyChallenge(req.GetChallengeToken())
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	userID, err := primitive.ObjectIDFromHex(payload.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := s.userDAO.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	// wrong codes count as failed logins of the account
+	addr := getClientAddrFromMetadata(ctx, s.loginConf.TrustedProxies)
+	if err := s.checkLoginLock(ctx, user.Account, addr); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.recordLoginFailure(ctx, user.Account, addr); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.loginAttemptDAO.Reset(ctx, loginAccountKey(user.Account)); err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.VerifyLoginChallengeResponse{
+		Token:        token,
+		UserId:       user.ID.Hex(),
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwtManager.TokenDuration().Seconds()),
+	}, nil
+}
+
+// verifySecondFactor accepts a TOTP code that was not used yet, or an unused recovery code which is used up.
+func (s *Service) verifySecondFactor(ctx context.Context, user *dao.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == otpkit.Digits {
+		counter, ok := otpkit.Validate(user.TOTPSecret, code, time.Now(), s.totpConf.Skew)
+		if !ok {
+			return false, nil
+		}
+
+		return s.userDAO.UseTOTPCounter(ctx, user.ID, counter)
+	}
+
+	return s.userDAO.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// getCaller returns the user calling an authenticated API.
+func (s *Service) getCaller(ctx context.Context) (*dao.User, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDAO.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// newRecoveryCodes returns codes formatted like abcde-fghij, and their hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/otpkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type totpTest struct {
+	svc     *Service
+	user    *dao.User
+	userDAO *fakeUserDAO
+	// recoveryCodes are the codes handed to the user, the user DAO only has their hashes
+	recoveryCodes []string
+}
+
+func newTOTPTest(t *testing.T) *totpTest {
+	t.Helper()
+
+	secret, err := otpkit.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := &totpTest{
+		user: &dao.User{
+			ID:            primitive.NewObjectID(),
+			Account:       "alice",
+			TOTPSecret:    secret,
+			TOTPEnabled:   true,
+			RecoveryCodes: hashes,
+		},
+		recoveryCodes: codes,
+	}
+	tt.userDAO = newFakeUserDAO(tt.user)
+
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	tt.svc = &Service{
+		userDAO:         tt.userDAO,
+		sessionDAO:      &fakeSessionDAO{},
+		loginAttemptDAO: newFakeLoginAttemptDAO(),
+		jwtManager: authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+			SecretKey:            "secret",
+			Issuer:               "blog-server",
+			Audience:             "blog-server",
+			TokenDuration:        15 * time.Minute,
+			RefreshTokenDuration: time.Hour,
+			ChallengeDuration:    5 * time.Minute,
+		}),
+		totpConf: &TOTPConfig{Skew: 1},
+		loginConf: &LoginLimitConfig{
+			AccountAttempts: 100,
+			AddrAttempts:    100,
+			Window:          time.Hour,
+			BaseLockout:     30 * time.Second,
+			MaxLockout:      2 * time.Minute,
+		},
+		logger: logger,
+	}
+
+	return tt
+}
+
+// verify answers a fresh login challenge of the user with code.
+func (tt *totpTest) verify(t *testing.T, code string) error {
+	t.Helper()
+
+	challenge, err := tt.svc.jwtManager.GenerateChallenge(tt.user.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := tt.svc.VerifyLoginChallenge(context.Background(), &pb.VerifyLoginChallengeRequest{ChallengeToken: challenge, Code: code})
+	if err == nil && resp.GetToken() == "" {
+		t.Fatal("the challenge was answered without an access token")
+	}
+
+	return err
+}
+
+// code returns the TOTP code of the time step offset steps away from the current one.
+func (tt *totpTest) code(t *testing.T, offset int64) string {
+	t.Helper()
+
+	code, err := otpkit.Code(tt.user.TOTPSecret, otpkit.Counter(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func TestVerifyLoginChallengeRejectsReplayedCodes(t *testing.T) {
+	tt := newTOTPTest(t)
+
+	if err := tt.verify(t, tt.code(t, 0)); err != nil {
+		t.Fatalf("the current code was rejected: %v", err)
+	}
+
+	// once a code is used, neither it nor the codes of earlier steps within the skew are accepted
+	for _, offset := range []int64{0, -1} {
+		if err := tt.verify(t, tt.code(t, offset)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("the code of step %+d after using the current one returned %v, want %v", offset, err, ErrInvalidCredentials)
+		}
+	}
+
+	// a later step within the skew is still accepted, once
+	next := tt.code(t, 1)
+	if err := tt.verify(t, next); err != nil {
+		t.Fatalf("the code of the next step was rejected: %v", err)
+	}
+	if err := tt.verify(t, next); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("the replayed code of the next step returned %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestVerifyLoginChallengeUsesUpRecoveryCodes(t *testing.T) {
+	tt := newTOTPTest(t)
+
+	// recovery codes are accepted however they are typed
+	code := strings.ToUpper(strings.Replace(tt.recoveryCodes[0], "-", " ", 1))
+	if err := tt.verify(t, code); err != nil {
+		t.Fatalf("the recovery code %q was rejected: %v", code, err)
+	}
+	if err := tt.verify(t, tt.recoveryCodes[0]); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("the used recovery code returned %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	user, err := tt.userDAO.Get(context.Background(), tt.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(user.RecoveryCodes) != recoveryCodeCount-1 {
+		t.Errorf("%d recovery codes are left, want %d", len(user.RecoveryCodes), recoveryCodeCount-1)
+	}
+
+	// the other codes are not used up with it
+	if err := tt.verify(t, tt.recoveryCodes[1]); err != nil {
+		t.Errorf("another recovery code was rejected: %v", err)
+	}
+	if err := tt.verify(t, "aaaaa-aaaaa"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("an unknown recovery code returned %v, want %v", err, ErrInvalidCredentials)
+	}
+}
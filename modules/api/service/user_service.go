@@ -3,34 +3,34 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/alice890308/blog-server/modules/api/dao"
 	"github.com/alice890308/blog-server/modules/api/pb"
+	"github.com/alice890308/blog-server/pkg/authkit"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 )
 
 func (s *Service) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	// check if the user account already exists
-	_, err := s.userDAO.GetByUserAccount(ctx, req.GetUserAccount())
-	if err == nil {
-		return nil, ErrUserAlreadyExists
-	} else if err != dao.ErrUserNotFound {
-		return nil, err
-	}
-
-	hashedPWD, err := bcrypt.GenerateFromPassword([]byte(req.GetUserPassword()), bcrypt.MinCost)
+	hashedPWD, err := s.hashPassword(req.GetUserPassword())
 	if err != nil {
-		return nil, ErrToHashPWD
+		return nil, err
 	}
 	user := &dao.User{
 		Name:     req.GetUserName(),
 		Account:  req.GetUserAccount(),
-		Password: string(hashedPWD),
+		Password: hashedPWD,
+		Role:     authkit.RoleAuthor,
 	}
 
+	// the unique index on the account rejects concurrent signups of the same account
 	err = s.userDAO.Create(ctx, user)
 	if err != nil {
+		if errors.Is(err, dao.ErrAccountExists) {
+			return nil, ErrUserAlreadyExists
+		}
+
 		return nil, err
 	}
 
@@ -56,25 +56,49 @@ func (s *Service) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetU
 }
 
 func (s *Service) ListUser(ctx context.Context, req *pb.ListUserRequest) (*pb.ListUserResponse, error) {
-	users, err := s.userDAO.List(ctx, req.GetLimit(), req.GetSkip())
+	cursor, err := decodePageToken(req.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 
+	users, next, err := s.userDAO.List(ctx, pageLimit(req.GetLimit()), req.GetSkip(), strings.TrimSpace(req.GetFilter()), cursor)
+	if err != nil {
+		// a page token of another listing, such as a search
+		if errors.Is(err, dao.ErrInvalidPageToken) {
+			return nil, ErrInvalidPageToken
+		}
+
+		return nil, err
+	}
+
 	pbUsers := make([]*pb.UserInfo, 0, len(users))
 	for _, user := range users {
 		pbUsers = append(pbUsers, user.ToProto())
 	}
 
-	return &pb.ListUserResponse{Users: pbUsers}, nil
+	return &pb.ListUserResponse{Users: pbUsers, NextPageToken: next.Encode()}, nil
 }
 
 func (s *Service) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	userID, err := getUserIDFromMetadata(ctx)
+	userID, err := getTargetUserID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := normalizeEmail(req.GetEmail())
 	if err != nil {
 		return nil, err
 	}
 
+	current, err := s.userDAO.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
 	user := &dao.User{
 		ID:          userID,
 		Name:        req.GetUserName(),
@@ -83,7 +107,9 @@ func (s *Service) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*p
 		IG:          req.GetIg(),
 		FB:          req.GetFb(),
 		TW:          req.GetTw(),
-		Email:       req.GetEmail(),
+		Email:       email,
+		// a new email has to be verified again
+		EmailVerified: current.EmailVerified && current.Email == email,
 	}
 
 	err = s.userDAO.Update(ctx, user)
@@ -91,15 +117,25 @@ func (s *Service) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*p
 		if errors.Is(err, dao.ErrUserNotFound) {
 			return nil, ErrUserNotFound
 		}
+		if errors.Is(err, dao.ErrEmailExists) {
+			return nil, ErrEmailAlreadyUsed
+		}
 
 		return nil, err
 	}
 
+	// the update is kept if the mail fails, SendEmailVerification sends it again
+	if email != "" && email != current.Email {
+		if err := s.sendEmailVerification(ctx, user); err != nil {
+			s.logger.Error("failed to send email verification", zap.String("user_id", userID.Hex()), zap.Error(err))
+		}
+	}
+
 	return &pb.UpdateUserResponse{}, nil
 }
 
 func (s *Service) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	userID, err := getUserIDFromMetadata(ctx)
+	userID, err := getTargetUserID(ctx, req.GetUserId())
 	if err != nil {
 		return nil, err
 	}
@@ -112,5 +148,232 @@ func (s *Service) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*p
 		return nil, err
 	}
 
+	if err := s.deleteFollows(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeUserSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.accessTokens.DeleteByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.identityDAO.DeleteByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	return &pb.DeleteUserResponse{}, nil
 }
+
+func (s *Service) Follow(ctx context.Context, req *pb.FollowRequest) (*pb.FollowResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	followeeID, err := primitive.ObjectIDFromHex(req.GetUserId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	if followeeID == userID {
+		return nil, ErrFollowSelf
+	}
+
+	if _, err := s.userDAO.Get(ctx, followeeID); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	created, err := s.followDAO.Create(ctx, userID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// following again is a no-op
+	if !created {
+		return &pb.FollowResponse{}, nil
+	}
+
+	if err := s.userDAO.UpdateFollowCounts(ctx, followeeID, 1, 0); err != nil {
+		return nil, err
+	}
+	if err := s.userDAO.UpdateFollowCounts(ctx, userID, 0, 1); err != nil {
+		return nil, err
+	}
+
+	return &pb.FollowResponse{}, nil
+}
+
+func (s *Service) Unfollow(ctx context.Context, req *pb.UnfollowRequest) (*pb.UnfollowResponse, error) {
+	userID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	followeeID, err := primitive.ObjectIDFromHex(req.GetUserId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	deleted, err := s.followDAO.Delete(ctx, userID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !deleted {
+		return &pb.UnfollowResponse{}, nil
+	}
+
+	if err := s.userDAO.UpdateFollowCounts(ctx, followeeID, -1, 0); err != nil && !errors.Is(err, dao.ErrUserNotFound) {
+		return nil, err
+	}
+	if err := s.userDAO.UpdateFollowCounts(ctx, userID, 0, -1); err != nil {
+		return nil, err
+	}
+
+	return &pb.UnfollowResponse{}, nil
+}
+
+func (s *Service) ListFollowers(ctx context.Context, req *pb.ListFollowersRequest) (*pb.ListFollowersResponse, error) {
+	userID, err := primitive.ObjectIDFromHex(req.GetUserId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	follows, err := s.followDAO.ListFollowers(ctx, userID, pageLimit(req.GetLimit()), req.GetSkip())
+	if err != nil {
+		return nil, err
+	}
+
+	followerIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		followerIDs = append(followerIDs, follow.FollowerID)
+	}
+
+	users, err := s.getUsersInOrder(ctx, followerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pbUsers := make([]*pb.UserInfo, 0, len(users))
+	for _, user := range users {
+		pbUsers = append(pbUsers, user.ToProto())
+	}
+
+	return &pb.ListFollowersResponse{Users: pbUsers}, nil
+}
+
+func (s *Service) ListFollowing(ctx context.Context, req *pb.ListFollowingRequest) (*pb.ListFollowingResponse, error) {
+	userID, err := primitive.ObjectIDFromHex(req.GetUserId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	follows, err := s.followDAO.ListFollowing(ctx, userID, pageLimit(req.GetLimit()), req.GetSkip())
+	if err != nil {
+		return nil, err
+	}
+
+	followeeIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		followeeIDs = append(followeeIDs, follow.FolloweeID)
+	}
+
+	users, err := s.getUsersInOrder(ctx, followeeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pbUsers := make([]*pb.UserInfo, 0, len(users))
+	for _, user := range users {
+		pbUsers = append(pbUsers, user.ToProto())
+	}
+
+	return &pb.ListFollowingResponse{Users: pbUsers}, nil
+}
+
+// deleteFollows removes the follows of a deleted user and keeps the counters of the other side consistent.
+func (s *Service) deleteFollows(ctx context.Context, userID primitive.ObjectID) error {
+	followerIDs, err := s.followDAO.ListFollowerIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range followerIDs {
+		if err := s.userDAO.UpdateFollowCounts(ctx, id, 0, -1); err != nil && !errors.Is(err, dao.ErrUserNotFound) {
+			return err
+		}
+	}
+
+	followeeIDs, err := s.followDAO.ListFolloweeIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range followeeIDs {
+		if err := s.userDAO.UpdateFollowCounts(ctx, id, -1, 0); err != nil && !errors.Is(err, dao.ErrUserNotFound) {
+			return err
+		}
+	}
+
+	return s.followDAO.DeleteByUserID(ctx, userID)
+}
+
+// SetUserRole changes the role of a user and ends their sessions, so that tokens carrying the old role stop working,
+// their personal access tokens take the new role.
+func (s *Service) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.SetUserRoleResponse, error) {
+	userID, err := primitive.ObjectIDFromHex(req.GetUserId())
+	if err != nil {
+		return nil, ErrInvalidObjectID
+	}
+
+	role := dao.UserRoleFromProto(req.GetRole())
+	if role == "" {
+		return nil, ErrInvalidUserRole
+	}
+
+	if err := s.userDAO.UpdateRole(ctx, userID, role); err != nil {
+		if errors.Is(err, dao.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	if err := s.accessTokens.UpdateRoleByUserID(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeUserSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return &pb.SetUserRoleResponse{}, nil
+}
+
+// getTargetUserID returns the user a request is about, only admins can act on other users than themselves.
+func getTargetUserID(ctx context.Context, hexUserID string) (primitive.ObjectID, error) {
+	callerID, err := getUserIDFromMetadata(ctx)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	if hexUserID == "" || hexUserID == callerID.Hex() {
+		return callerID, nil
+	}
+
+	if !getRoleFromMetadata(ctx).AtLeast(authkit.RoleAdmin) {
+		return primitive.NilObjectID, ErrPermissionDenied
+	}
+
+	userID, err := primitive.ObjectIDFromHex(hexUserID)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidObjectID
+	}
+
+	return userID, nil
+}
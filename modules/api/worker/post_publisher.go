@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.uber.org/zap"
+)
+
+type PublisherConfig struct {
+	Interval time.Duration `long:"interval" env:"INTERVAL" description:"interval to publish scheduled posts" default:"30s"`
+}
+
+// PostPublisher periodically publishes the scheduled posts whose publish time has come.
+type PostPublisher struct {
+	postDAO dao.PostDAO
+	conf    *PublisherConfig
+	logger  *logkit.Logger
+}
+
+func NewPostPublisher(postDAO dao.PostDAO, conf *PublisherConfig, logger *logkit.Logger) *PostPublisher {
+	return &PostPublisher{
+		postDAO: postDAO,
+		conf:    conf,
+		logger:  logger,
+	}
+}
+
+// Run publishes scheduled posts every Interval until ctx is done.
+func (p *PostPublisher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.publish(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *PostPublisher) publish(ctx context.Context) {
+	n, err := p.postDAO.PublishScheduled(ctx, time.Now())
+	if err != nil {
+		p.logger.Error("failed to publish scheduled posts", zap.Error(err))
+		return
+	}
+
+	if n > 0 {
+		p.logger.Info("published scheduled posts", zap.Int64("count", n))
+	}
+}
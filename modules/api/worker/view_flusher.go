@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/api/dao"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.uber.org/zap"
+)
+
+type ViewConfig struct {
+	Window        time.Duration `long:"window" env:"WINDOW" description:"a viewer is counted once per post within the window" default:"30m"`
+	FlushInterval time.Duration `long:"flush_interval" env:"FLUSH_INTERVAL" description:"interval to flush aggregated views to MongoDB" default:"1m"`
+	FlushTimeout  time.Duration `long:"flush_timeout" env:"FLUSH_TIMEOUT" description:"timeout of the final flush on shutdown" default:"5s"`
+}
+
+// ViewFlusher periodically moves the views aggregated by the ViewDAO into the posts.
+type ViewFlusher struct {
+	viewDAO dao.ViewDAO
+	postDAO dao.PostDAO
+	conf    *ViewConfig
+	logger  *logkit.Logger
+}
+
+func NewViewFlusher(viewDAO dao.ViewDAO, postDAO dao.PostDAO, conf *ViewConfig, logger *logkit.Logger) *ViewFlusher {
+	return &ViewFlusher{
+		viewDAO: viewDAO,
+		postDAO: postDAO,
+		conf:    conf,
+		logger:  logger,
+	}
+}
+
+// Run flushes views every FlushInterval until ctx is done, then flushes one last time.
+func (f *ViewFlusher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.conf.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), f.conf.FlushTimeout)
+			defer cancel()
+
+			f.flush(flushCtx)
+			return nil
+		}
+	}
+}
+
+func (f *ViewFlusher) flush(ctx context.Context) {
+	views, err := f.viewDAO.PopPending(ctx)
+	if err != nil {
+		f.logger.Error("failed to pop pending views", zap.Error(err))
+		return
+	}
+
+	for postID, n := range views {
+		err := f.postDAO.UpdateViews(ctx, postID, n)
+		if err == nil {
+			continue
+		}
+
+		// views of deleted posts are dropped
+		if errors.Is(err, dao.ErrPostNotFound) {
+			continue
+		}
+
+		f.logger.Error("failed to flush views", zap.String("post_id", postID.Hex()), zap.Error(err))
+		if err := f.viewDAO.PushPending(ctx, postID, n); err != nil {
+			f.logger.Error("failed to push back views", zap.String("post_id", postID.Hex()), zap.Int("views", n), zap.Error(err))
+		}
+	}
+}
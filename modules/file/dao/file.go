@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Variant is one stored encoding of an uploaded image.
+type Variant struct {
+	Name        string `bson:"name"`
+	Key         string `bson:"key"`
+	Size        int64  `bson:"size"`
+	ContentType string `bson:"content_type"`
+	Width       int    `bson:"width"`
+	Height      int    `bson:"height"`
+}
+
+// File is the metadata of an upload, Size is the total of its variants.
+type File struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id,omitempty"`
+	Name        string             `bson:"name,omitempty"`
+	Size        int64              `bson:"size,omitempty"`
+	ContentType string             `bson:"content_type,omitempty"`
+	Variants    []*Variant         `bson:"variants,omitempty"`
+	CreatedAT   time.Time          `bson:"created_at,omitempty"`
+}
+
+// Keys returns the storage keys of every variant of the file.
+func (f *File) Keys() []string {
+	keys := make([]string, 0, len(f.Variants))
+	for _, variant := range f.Variants {
+		keys = append(keys, variant.Key)
+	}
+
+	return keys
+}
+
+type FileDAO interface {
+	Create(ctx context.Context, file *File) error
+	// Get returns the file only if it belongs to the user.
+	Get(ctx context.Context, id, userID primitive.ObjectID) (*File, error)
+	// ListByUserID returns the files of the user newest first, after the file before unless it is zero, and
+	// the ID to list the next page after, zero on the last page.
+	ListByUserID(ctx context.Context, userID primitive.ObjectID, limit int64, before primitive.ObjectID) ([]*File, primitive.ObjectID, error)
+	// Delete removes the file only if it belongs to the user.
+	Delete(ctx context.Context, id, userID primitive.ObjectID) error
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrFileNotFound = errors.New("file not found")
+)
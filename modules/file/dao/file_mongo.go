@@ -0,0 +1,99 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoFileDAO struct {
+	collection *mongo.Collection
+}
+
+var _ FileDAO = (*mongoFileDAO)(nil)
+
+func NewMongoFileDAO(collection *mongo.Collection) *mongoFileDAO {
+	return &mongoFileDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoFileDAO) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys: bson.D{{"user_id", 1}, {"_id", -1}},
+		},
+	}
+	_, err := dao.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dao *mongoFileDAO) Create(ctx context.Context, file *File) error {
+	_, err := dao.collection.InsertOne(ctx, file)
+
+	return err
+}
+
+func (dao *mongoFileDAO) Get(ctx context.Context, id, userID primitive.ObjectID) (*File, error) {
+	var file File
+	err := dao.collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&file)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrFileNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (dao *mongoFileDAO) ListByUserID(
+	ctx context.Context,
+	userID primitive.ObjectID,
+	limit int64,
+	before primitive.ObjectID,
+) ([]*File, primitive.ObjectID, error) {
+	filter := bson.M{"user_id": userID}
+	if !before.IsZero() {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+
+	// fetch one more file to know whether there is a next page
+	o := options.Find().SetLimit(limit + 1).SetSort(bson.D{{"_id", -1}})
+
+	cursor, err := dao.collection.Find(ctx, filter, o)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	defer cursor.Close(ctx)
+
+	files := make([]*File, 0)
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	if int64(len(files)) <= limit {
+		return files, primitive.NilObjectID, nil
+	}
+
+	files = files[:limit]
+	return files, files[limit-1].ID, nil
+}
+
+func (dao *mongoFileDAO) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	result, err := dao.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrFileNotFound
+	}
+
+	return nil
+}
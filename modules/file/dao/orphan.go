@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"context"
+	"time"
+)
+
+// Orphan records since when a stored object is referenced by no post or user.
+type Orphan struct {
+	Key        string    `bson:"_id"`
+	OrphanedAT time.Time `bson:"orphaned_at"`
+}
+
+type OrphanDAO interface {
+	// List returns when each orphaned key was first found orphaned.
+	List(ctx context.Context) (map[string]time.Time, error)
+	// Mark records the keys as orphaned at now, the keys already marked keep their time.
+	Mark(ctx context.Context, keys []string, now time.Time) error
+	// Unmark forgets the keys, once they are referenced again or removed.
+	Unmark(ctx context.Context, keys []string) error
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoOrphanDAO struct {
+	collection *mongo.Collection
+}
+
+var _ OrphanDAO = (*mongoOrphanDAO)(nil)
+
+func NewMongoOrphanDAO(collection *mongo.Collection) *mongoOrphanDAO {
+	return &mongoOrphanDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoOrphanDAO) List(ctx context.Context) (map[string]time.Time, error) {
+	cursor, err := dao.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	orphans := make(map[string]time.Time)
+	for cursor.Next(ctx) {
+		var orphan Orphan
+		if err := cursor.Decode(&orphan); err != nil {
+			return nil, err
+		}
+
+		orphans[orphan.Key] = orphan.OrphanedAT
+	}
+
+	return orphans, cursor.Err()
+}
+
+func (dao *mongoOrphanDAO) Mark(ctx context.Context, keys []string, now time.Time) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(keys))
+	for _, key := range keys {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": key}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"orphaned_at": now}}).
+			SetUpsert(true))
+	}
+
+	_, err := dao.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
+
+func (dao *mongoOrphanDAO) Unmark(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := dao.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": keys}})
+
+	return err
+}
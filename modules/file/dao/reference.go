@@ -0,0 +1,26 @@
+package dao
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReferencePost = "post"
+	ReferenceUser = "user"
+)
+
+// Reference is a document of the api service that shows a file, a post image or a user avator.
+type Reference struct {
+	Kind string
+	ID   primitive.ObjectID
+}
+
+type ReferenceDAO interface {
+	// Find returns the posts and users whose image or avator points at any of the keys.
+	Find(ctx context.Context, keys []string) ([]*Reference, error)
+	// Walk calls fn with every text that can point at files: the image and content of posts and of their
+	// revisions, which can be restored, and the avator of users.
+	Walk(ctx context.Context, fn func(text string)) error
+}
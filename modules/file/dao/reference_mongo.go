@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxReferences bounds the references returned of each kind, a few are enough to explain a refusal.
+const maxReferences = 10
+
+type mongoReferenceDAO struct {
+	posts     *mongo.Collection
+	revisions *mongo.Collection
+	users     *mongo.Collection
+}
+
+var _ ReferenceDAO = (*mongoReferenceDAO)(nil)
+
+func NewMongoReferenceDAO(posts, revisions, users *mongo.Collection) *mongoReferenceDAO {
+	return &mongoReferenceDAO{
+		posts:     posts,
+		revisions: revisions,
+		users:     users,
+	}
+}
+
+func (dao *mongoReferenceDAO) Find(ctx context.Context, keys []string) ([]*Reference, error) {
+	if len(keys) == 0 {
+		return []*Reference{}, nil
+	}
+
+	// the fields hold the path of the file, relative or as a full URL, so the key is matched at the end
+	patterns := make(bson.A, 0, len(keys))
+	for _, key := range keys {
+		patterns = append(patterns, primitive.Regex{Pattern: "(^|/)" + regexp.QuoteMeta(key) + "$"})
+	}
+
+	references := make([]*Reference, 0)
+	for _, source := range []struct {
+		kind       string
+		collection *mongo.Collection
+		field      string
+	}{
+		{ReferencePost, dao.posts, "image"},
+		{ReferenceUser, dao.users, "avator"},
+	} {
+		o := options.Find().SetLimit(maxReferences).SetProjection(bson.M{"_id": 1})
+
+		cursor, err := source.collection.Find(ctx, bson.M{source.field: bson.M{"$in": patterns}}, o)
+		if err != nil {
+			return nil, err
+		}
+
+		var docs []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err = cursor.All(ctx, &docs)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			references = append(references, &Reference{Kind: source.kind, ID: doc.ID})
+		}
+	}
+
+	return references, nil
+}
+
+func (dao *mongoReferenceDAO) Walk(ctx context.Context, fn func(text string)) error {
+	for _, source := range []struct {
+		collection *mongo.Collection
+		fields     []string
+	}{
+		{dao.posts, []string{"image", "content"}},
+		{dao.revisions, []string{"image", "content"}},
+		{dao.users, []string{"avator"}},
+	} {
+		projection := bson.M{"_id": 0}
+		for _, field := range source.fields {
+			projection[field] = 1
+		}
+
+		cursor, err := source.collection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+		if err != nil {
+			return err
+		}
+
+		for cursor.Next(ctx) {
+			var doc map[string]interface{}
+			if err := cursor.Decode(&doc); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+
+			for _, field := range source.fields {
+				if text, ok := doc[field].(string); ok && text != "" {
+					fn(text)
+				}
+			}
+		}
+
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Usage is the storage a user takes up with uploads, variants included.
+type Usage struct {
+	UserID primitive.ObjectID `bson:"_id,omitempty"`
+	Bytes  int64              `bson:"bytes"`
+	Files  int64              `bson:"files"`
+}
+
+type UsageDAO interface {
+	// Reserve adds an upload of size bytes to the usage of the user, it returns false when that would exceed
+	// maxBytes or maxFiles. A limit of 0 is unlimited.
+	Reserve(ctx context.Context, userID primitive.ObjectID, size, maxBytes, maxFiles int64) (bool, error)
+	// Release removes an upload of size bytes from the usage of the user.
+	Release(ctx context.Context, userID primitive.ObjectID, size int64) error
+}
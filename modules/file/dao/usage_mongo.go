@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUsageDAO struct {
+	collection *mongo.Collection
+}
+
+var _ UsageDAO = (*mongoUsageDAO)(nil)
+
+func NewMongoUsageDAO(collection *mongo.Collection) *mongoUsageDAO {
+	return &mongoUsageDAO{
+		collection: collection,
+	}
+}
+
+func (dao *mongoUsageDAO) Reserve(ctx context.Context, userID primitive.ObjectID, size, maxBytes, maxFiles int64) (bool, error) {
+	if maxBytes > 0 && size > maxBytes {
+		return false, nil
+	}
+
+	// the limits are checked in the filter, so that concurrent uploads can not overshoot them together
+	filter := bson.M{"_id": userID}
+	if maxBytes > 0 {
+		filter["bytes"] = bson.M{"$lte": maxBytes - size}
+	}
+	if maxFiles > 0 {
+		filter["files"] = bson.M{"$lte": maxFiles - 1}
+	}
+
+	_, err := dao.collection.UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$inc": bson.M{"bytes": size, "files": 1}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		// the usage exists but does not match the limits, so the upsert tried to insert it again
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (dao *mongoUsageDAO) Release(ctx context.Context, userID primitive.ObjectID, size int64) error {
+	_, err := dao.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{"$inc": bson.M{"bytes": -size, "files": -1}},
+	)
+
+	return err
+}
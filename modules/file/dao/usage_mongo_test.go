@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// sentUpdate returns the filter and the update of the single update command sent.
+func sentUpdate(mt *mtest.T) (bson.Raw, bson.Raw) {
+	event := mt.GetStartedEvent()
+	if event == nil || event.CommandName != "update" {
+		mt.Fatalf("sent %v, want an update", event)
+	}
+
+	update := event.Command.Lookup("updates").Array().Index(0).Value().Document()
+	return update.Lookup("q").Document(), update.Lookup("u").Document()
+}
+
+func TestMongoUsageDAOReserve(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	upserted := mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 0}, bson.E{"upserted", bson.A{bson.D{{"index", 0}, {"_id", primitive.NewObjectID()}}}})
+	updated := mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1})
+	// the usage exists but the filter did not match it, so the upsert collided with it
+	overQuota := mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"})
+
+	tests := []struct {
+		name               string
+		size               int64
+		maxBytes, maxFiles int64
+		response           bson.D
+		want               bool
+		// wantBytes and wantFiles are the bounds the stored usage must be within for the upload to fit
+		wantBytes, wantFiles int64
+	}{
+		{name: "first upload", size: 100, maxBytes: 1000, maxFiles: 10, response: upserted, want: true, wantBytes: 900, wantFiles: 9},
+		{name: "within the quota", size: 100, maxBytes: 1000, maxFiles: 10, response: updated, want: true, wantBytes: 900, wantFiles: 9},
+		{name: "over the quota", size: 100, maxBytes: 1000, maxFiles: 10, response: overQuota, want: false, wantBytes: 900, wantFiles: 9},
+		{name: "unlimited", size: 100, response: updated, want: true},
+	}
+
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(test.response)
+
+			ok, err := NewMongoUsageDAO(mt.Coll).Reserve(context.Background(), primitive.NewObjectID(), test.size, test.maxBytes, test.maxFiles)
+			if err != nil {
+				mt.Fatal(err)
+			}
+			if ok != test.want {
+				mt.Errorf("reserved: %v, want %v", ok, test.want)
+			}
+
+			filter, update := sentUpdate(mt)
+			for _, bound := range []struct {
+				field string
+				want  int64
+			}{
+				{"bytes", test.wantBytes},
+				{"files", test.wantFiles},
+			} {
+				value, err := filter.LookupErr(bound.field, "$lte")
+				if bound.want == 0 {
+					if err == nil {
+						mt.Errorf("the unlimited %s are bounded by %v", bound.field, value)
+					}
+					continue
+				}
+				if err != nil || value.AsInt64() != bound.want {
+					mt.Errorf("the %s are bounded by %v, want at most %d", bound.field, value, bound.want)
+				}
+			}
+			if size := update.Lookup("$inc", "bytes").AsInt64(); size != test.size {
+				mt.Errorf("added %d bytes, want %d", size, test.size)
+			}
+		})
+	}
+
+	mt.Run("larger than the quota", func(mt *mtest.T) {
+		ok, err := NewMongoUsageDAO(mt.Coll).Reserve(context.Background(), primitive.NewObjectID(), 1001, 1000, 10)
+		if err != nil || ok {
+			mt.Errorf("reserved: %v, %v, want the upload refused", ok, err)
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("sent %s for an upload that can never fit", event.CommandName)
+		}
+	})
+}
+
+func TestMongoUsageDAOReleaseUndoesReserve(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("release", func(mt *mtest.T) {
+		dao := NewMongoUsageDAO(mt.Coll)
+		userID := primitive.NewObjectID()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}))
+		if _, err := dao.Reserve(context.Background(), userID, 100, 1000, 10); err != nil {
+			mt.Fatal(err)
+		}
+		_, reserved := sentUpdate(mt)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{"n", 1}, bson.E{"nModified", 1}))
+		if err := dao.Release(context.Background(), userID, 100); err != nil {
+			mt.Fatal(err)
+		}
+		filter, released := sentUpdate(mt)
+
+		if id := filter.Lookup("_id").ObjectID(); id != userID {
+			mt.Errorf("released the usage of %s, want %s", id.Hex(), userID.Hex())
+		}
+		for _, field := range []string{"bytes", "files"} {
+			add, remove := reserved.Lookup("$inc", field).AsInt64(), released.Lookup("$inc", field).AsInt64()
+			if add+remove != 0 {
+				mt.Errorf("reserved %d %s and released %d, want them to cancel out", add, field, -remove)
+			}
+		}
+	})
+}
@@ -1,29 +1,71 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"io"
-	"io/fs"
-	"log"
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alice890308/blog-server/modules/file/dao"
+	"github.com/alice890308/blog-server/modules/file/worker"
 	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/imagekit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/storagekit"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Service struct {
-	authkit.JWT
+	auth         *authkit.AuthService
+	usageDAO     dao.UsageDAO
+	fileDAO      dao.FileDAO
+	referenceDAO dao.ReferenceDAO
+	storage      storagekit.Storage
+	uploadConf   *UploadConfig
+	imageConf    *imagekit.ImageConfig
+	logger       *logkit.Logger
 }
 
 const (
-	bufferSize int = 512
-	dirAuth    int = 0666
-	fileAuth   int = 0644
+	// multipartOverhead bounds the boundaries and part headers around the file in an upload request
+	multipartOverhead int64 = 64 << 10
+	// a key is never reused, so a stored file never changes
+	cacheControl = "public, max-age=31536000, immutable"
+	// staticPrefix is the path the stored files are served under, relative to the service
+	staticPrefix = "static/"
 )
 
-func NewService(jwtManager authkit.JWT) *Service {
-	return &Service{jwtManager}
+// variantNames are ordered from the largest variant to the smallest.
+var variantNames = []string{imagekit.VariantOriginal, imagekit.VariantMedium, imagekit.VariantThumbnail}
+
+// filesRule lets every signed in user manage their files, personal access tokens need the files:upload scope.
+var filesRule = authkit.Rule{Role: authkit.RoleReader, Scope: authkit.ScopeFilesUpload}
+
+type UploadConfig struct {
+	MaxSize    int64 `long:"max_size" env:"MAX_SIZE" description:"largest file accepted in bytes" default:"10485760"`
+	QuotaBytes int64 `long:"quota_bytes" env:"QUOTA_BYTES" description:"bytes a user can store, variants included, 0 is unlimited" default:"1073741824"`
+	QuotaFiles int64 `long:"quota_files" env:"QUOTA_FILES" description:"uploads a user can store, 0 is unlimited" default:"1000"`
+}
+
+func NewService(
+	auth *authkit.AuthService,
+	usageDAO dao.UsageDAO,
+	fileDAO dao.FileDAO,
+	referenceDAO dao.ReferenceDAO,
+	storage storagekit.Storage,
+	uploadConf *UploadConfig,
+	imageConf *imagekit.ImageConfig,
+	logger *logkit.Logger,
+) *Service {
+	return &Service{auth, usageDAO, fileDAO, referenceDAO, storage, uploadConf, imageConf, logger}
 }
 
 func (s *Service) Status(c *gin.Context) {
@@ -33,23 +75,25 @@ func (s *Service) Status(c *gin.Context) {
 }
 
 func (s *Service) Upload(c *gin.Context) {
-	userID := s.getUserID(c.Request.Header["Authorization"][0])
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "invalid token",
-		})
+	userID, ok := s.authenticate(c)
+	if !ok {
 		return
 	}
 
-	err := checkDir(userID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "check dir error",
-		})
+	// the size is checked while the body is read, as the Content-Length can be missing or wrong
+	maxRequestSize := s.uploadConf.MaxSize + multipartOverhead
+	if c.Request.ContentLength > maxRequestSize {
+		abortTooLarge(c, "file is larger than "+strconv.FormatInt(s.uploadConf.MaxSize, 10)+" bytes")
 		return
 	}
+	body := &maxBytesReader{ReadCloser: c.Request.Body, n: maxRequestSize}
+	c.Request.Body = body
 
 	fileHeader, err := c.FormFile("file")
+	if body.exceeded || (err == nil && fileHeader.Size > s.uploadConf.MaxSize) {
+		abortTooLarge(c, "file is larger than "+strconv.FormatInt(s.uploadConf.MaxSize, 10)+" bytes")
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"message": "get formfile error",
@@ -57,7 +101,6 @@ func (s *Service) Upload(c *gin.Context) {
 		return
 	}
 
-	buffer := make([]byte, bufferSize)
 	file, err := fileHeader.Open()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -65,81 +108,262 @@ func (s *Service) Upload(c *gin.Context) {
 		})
 		return
 	}
+	defer file.Close()
 
-	_, err = file.Read(buffer)
+	fileBytes, err := io.ReadAll(file)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "file read to buffer error",
+			"message": "file read error",
 		})
 		return
 	}
 
-	contentType := http.DetectContentType(buffer)
-	_, err = file.Seek(0, io.SeekStart)
+	// the whole image is decoded, which rejects the files that only start like an image
+	variants, err := imagekit.Process(fileBytes, s.imageConf)
+	if errors.Is(err, imagekit.ErrTooManyPixels) {
+		abortTooLarge(c, err.Error())
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "file seek error",
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"message": "file is not a jpeg or png image",
 		})
 		return
 	}
 
-	fileBytes, err := io.ReadAll(file)
+	var size int64
+	for _, variant := range variants {
+		size += int64(len(variant.Data))
+	}
+
+	ok, err = s.usageDAO.Reserve(c.Request.Context(), userID, size, s.uploadConf.QuotaBytes, s.uploadConf.QuotaFiles)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "file read error",
+		s.logger.Error("failed to reserve storage quota", zap.String("user_id", userID.Hex()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "check quota error",
 		})
 		return
 	}
+	if !ok {
+		abortTooLarge(c, "storage quota exceeded")
+		return
+	}
 
-	filePath := "static/" + userID + "/" + uuid.New().String()
-	switch contentType {
-	case "image/jpg":
-		filePath += ".jpg"
-	case "image/png":
-		filePath += ".png"
-	case "image/jpeg":
-		filePath += ".jpeg"
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "file type error",
+	record := &dao.File{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Name:        fileHeader.Filename,
+		Size:        size,
+		ContentType: variants[0].ContentType,
+		CreatedAT:   time.Now(),
+	}
+
+	record.Variants, err = s.putVariants(c.Request.Context(), userID.Hex()+"/"+record.ID.Hex(), variants)
+	if err != nil {
+		s.releaseQuota(c.Request.Context(), userID, size)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "store file error",
 		})
 		return
 	}
 
-	err = os.WriteFile(filePath, fileBytes, fs.FileMode(fileAuth))
+	err = s.fileDAO.Create(c.Request.Context(), record)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": err.Error(),
+		s.logger.Error("failed to create file", zap.String("file_id", record.ID.Hex()), zap.Error(err))
+		s.deleteKeys(c.Request.Context(), record.Keys())
+		s.releaseQuota(c.Request.Context(), userID, size)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "store file error",
 		})
 		return
 	}
 
+	paths := variantPaths(record.Variants)
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":  "success",
-		"filepath": filePath,
+		"id":       record.ID.Hex(),
+		"filepath": paths[imagekit.VariantOriginal],
+		"variants": paths,
 	})
 }
 
-func (s *Service) getUserID(accessToken string) string {
-	accessToken = accessToken[7:]
-	log.Println(accessToken)
+// putVariants stores the variants of an image under the key prefix, nothing is left behind on failure.
+func (s *Service) putVariants(ctx context.Context, prefix string, variants []*imagekit.Variant) ([]*dao.Variant, error) {
+	stored := make([]*dao.Variant, 0, len(variants))
+	for _, variant := range variants {
+		key := prefix
+		if variant.Name != imagekit.VariantOriginal {
+			key += "_" + variant.Name
+		}
+		key += "." + variant.Format
+
+		err := s.storage.Put(ctx, key, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.ContentType)
+		if err != nil {
+			s.logger.Error("failed to store file", zap.String("key", key), zap.Error(err))
+			s.deleteKeys(ctx, (&dao.File{Variants: stored}).Keys())
+			return nil, err
+		}
+
+		stored = append(stored, &dao.Variant{
+			Name:        variant.Name,
+			Key:         key,
+			Size:        int64(len(variant.Data)),
+			ContentType: variant.ContentType,
+			Width:       variant.Width,
+			Height:      variant.Height,
+		})
+	}
+
+	return stored, nil
+}
+
+// variantPaths returns the path of every variant name. A variant left out because the image was already small
+// enough shares the path of the larger one.
+func variantPaths(variants []*dao.Variant) map[string]string {
+	stored := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		stored[variant.Name] = staticPrefix + variant.Key
+	}
+
+	paths := make(map[string]string, len(variantNames))
+	var last string
+	for _, name := range variantNames {
+		if path, ok := stored[name]; ok {
+			last = path
+		}
+		paths[name] = last
+	}
+
+	return paths
+}
+
+// deleteKeys removes stored objects on a best effort basis, the ones left behind are only wasted space.
+func (s *Service) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			s.logger.Warn("failed to delete file", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func (s *Service) releaseQuota(ctx context.Context, userID primitive.ObjectID, size int64) {
+	if err := s.usageDAO.Release(ctx, userID, size); err != nil {
+		s.logger.Error("failed to release storage quota", zap.String("user_id", userID.Hex()), zap.Error(err))
+	}
+}
+
+// Download serves the stored files under /static/*key, for GET as well as HEAD.
+func (s *Service) Download(c *gin.Context) {
+	ctx := c.Request.Context()
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if strings.HasPrefix(key, worker.QuarantinePrefix) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "file not found",
+		})
+		return
+	}
 
-	payload, err := s.JWT.Verify(accessToken)
+	var (
+		body   io.ReadCloser
+		object *storagekit.Object
+		err    error
+	)
+	if c.Request.Method == http.MethodHead {
+		object, err = s.storage.Stat(ctx, key)
+	} else {
+		body, object, err = s.storage.Get(ctx, key)
+	}
+	if errors.Is(err, storagekit.ErrNotExist) || errors.Is(err, storagekit.ErrInvalidKey) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "file not found",
+		})
+		return
+	}
 	if err != nil {
-		return ""
+		s.logger.Error("failed to read file", zap.String("key", key), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "read file error",
+		})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", object.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	header.Set("Cache-Control", cacheControl)
+	header.Set("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	if body == nil {
+		return
 	}
+	defer body.Close()
 
-	return payload.UserID
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		s.logger.Warn("failed to send file", zap.String("key", key), zap.Error(err))
+	}
 }
 
-func checkDir(userID string) error {
-	path := "/static/" + userID
-	_, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		if e := os.Mkdir(path, fs.FileMode(dirAuth)); e != nil {
-			return e
+// authenticate returns the ID of the caller, or responds with the error and returns false.
+func (s *Service) authenticate(c *gin.Context) (primitive.ObjectID, bool) {
+	payload, err := s.auth.Authorize(c.Request.Context(), c.GetHeader("Authorization"), filesRule)
+	if err != nil {
+		code := http.StatusUnauthorized
+		switch status.Code(err) {
+		case codes.PermissionDenied:
+			code = http.StatusForbidden
+		case codes.Unavailable:
+			code = http.StatusServiceUnavailable
 		}
+		c.JSON(code, gin.H{
+			"message": status.Convert(err).Message(),
+		})
+		return primitive.NilObjectID, false
+	}
+
+	id, err := primitive.ObjectIDFromHex(payload.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "invalid token",
+		})
+		return primitive.NilObjectID, false
+	}
+
+	return id, true
+}
+
+func abortTooLarge(c *gin.Context, message string) {
+	// the rest of the body is not worth reading
+	c.Header("Connection", "close")
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"message": message,
+	})
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// maxBytesReader fails the reads past n bytes, and remembers it since the multipart parser hides the error.
+type maxBytesReader struct {
+	io.ReadCloser
+	n        int64
+	exceeded bool
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, errBodyTooLarge
+	}
+	// one more byte than allowed is read to tell a body of exactly n bytes from a longer one
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) > r.n {
+		r.exceeded = true
+		return int(r.n), errBodyTooLarge
 	}
+	r.n -= int64(n)
 
-	return nil
+	return n, err
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeDenylist struct {
+	revoked map[string]bool
+}
+
+func (f *fakeDenylist) Revoke(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		f.revoked[id] = true
+	}
+
+	return nil
+}
+
+func (f *fakeDenylist) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	for _, id := range ids {
+		if f.revoked[id] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type fakeAccessTokenStore struct {
+	authkit.AccessTokenStore
+
+	tokens map[string]*authkit.AccessToken
+}
+
+func (f *fakeAccessTokenStore) Verify(ctx context.Context, token string) (*authkit.AccessToken, error) {
+	accessToken, ok := f.tokens[token]
+	if !ok {
+		return nil, authkit.ErrAccessTokenNotFound
+	}
+
+	return accessToken, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	jwtManager := authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+		SecretKey:     "secret",
+		Issuer:        "blog-server",
+		Audience:      "blog-server",
+		TokenDuration: 15 * time.Minute,
+	})
+	userID := primitive.NewObjectID()
+	valid, err := jwtManager.Generate(userID.Hex(), "session", authkit.RoleReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := jwtManager.Generate(userID.Hex(), "revoked-session", authkit.RoleReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &fakeAccessTokenStore{tokens: map[string]*authkit.AccessToken{
+		"pat_files": {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleReader, Scopes: []string{authkit.ScopeFilesUpload}},
+		"pat_posts": {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleReader, Scopes: []string{authkit.ScopePostsWrite}},
+	}}
+	denylist := &fakeDenylist{revoked: map[string]bool{"revoked-session": true}}
+	s := &Service{auth: authkit.NewAuthService(jwtManager, denylist, nil, store), logger: logger}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "jwt", authorization: "Bearer " + valid, want: http.StatusOK},
+		{name: "access token", authorization: "Bearer pat_files", want: http.StatusOK},
+		{name: "no token", want: http.StatusUnauthorized},
+		{name: "invalid token", authorization: "Bearer invalid", want: http.StatusUnauthorized},
+		{name: "revoked session", authorization: "Bearer " + revoked, want: http.StatusUnauthorized},
+		{name: "access token without the scope", authorization: "Bearer pat_posts", want: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/files", nil)
+			if test.authorization != "" {
+				c.Request.Header.Set("Authorization", test.authorization)
+			}
+
+			id, ok := s.authenticate(c)
+			if ok {
+				c.Status(http.StatusOK)
+				if id != userID {
+					t.Errorf("authenticated %s, want %s", id.Hex(), userID.Hex())
+				}
+			}
+			if w.Code != test.want {
+				t.Errorf("responded %d, want %d", w.Code, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alice890308/blog-server/modules/file/dao"
+	"github.com/alice890308/blog-server/pkg/imagekit"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize int64 = 20
+	maxPageSize     int64 = 100
+)
+
+// ListFiles returns the uploads of the caller newest first, the next_page_token of a page is the page_token
+// of the next one.
+func (s *Service) ListFiles(c *gin.Context) {
+	userID, ok := s.authenticate(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultPageSize
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "invalid limit",
+			})
+			return
+		}
+		if limit > maxPageSize {
+			limit = maxPageSize
+		}
+	}
+
+	var before primitive.ObjectID
+	if token := c.Query("page_token"); token != "" {
+		var err error
+		before, err = primitive.ObjectIDFromHex(token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "invalid page token",
+			})
+			return
+		}
+	}
+
+	files, next, err := s.fileDAO.ListByUserID(c.Request.Context(), userID, limit, before)
+	if err != nil {
+		s.logger.Error("failed to list files", zap.String("user_id", userID.Hex()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "list files error",
+		})
+		return
+	}
+
+	items := make([]gin.H, 0, len(files))
+	for _, file := range files {
+		items = append(items, fileToJSON(file))
+	}
+
+	var nextPageToken string
+	if !next.IsZero() {
+		nextPageToken = next.Hex()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"files":           items,
+		"next_page_token": nextPageToken,
+	})
+}
+
+func (s *Service) GetFile(c *gin.Context) {
+	userID, ok := s.authenticate(c)
+	if !ok {
+		return
+	}
+
+	file, ok := s.getFile(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, fileToJSON(file))
+}
+
+// DeleteFile removes an upload with all its variants. It refuses with 409 while a post image or a user avator
+// still points at the file, as they would show a broken image.
+func (s *Service) DeleteFile(c *gin.Context) {
+	userID, ok := s.authenticate(c)
+	if !ok {
+		return
+	}
+
+	file, ok := s.getFile(c, userID)
+	if !ok {
+		return
+	}
+
+	references, err := s.referenceDAO.Find(c.Request.Context(), file.Keys())
+	if err != nil {
+		s.logger.Error("failed to find file references", zap.String("file_id", file.ID.Hex()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "find references error",
+		})
+		return
+	}
+	if len(references) > 0 {
+		items := make([]gin.H, 0, len(references))
+		for _, reference := range references {
+			items = append(items, gin.H{
+				"kind": reference.Kind,
+				"id":   reference.ID.Hex(),
+			})
+		}
+
+		c.JSON(http.StatusConflict, gin.H{
+			"message":    "file is still used",
+			"references": items,
+		})
+		return
+	}
+
+	err = s.fileDAO.Delete(c.Request.Context(), file.ID, userID)
+	if errors.Is(err, dao.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "file not found",
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to delete file", zap.String("file_id", file.ID.Hex()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "delete file error",
+		})
+		return
+	}
+
+	s.deleteKeys(c.Request.Context(), file.Keys())
+	s.releaseQuota(c.Request.Context(), userID, file.Size)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "success",
+	})
+}
+
+// getFile returns the file of the id path parameter, or responds with the error and returns false.
+func (s *Service) getFile(c *gin.Context, userID primitive.ObjectID) (*dao.File, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "file not found",
+		})
+		return nil, false
+	}
+
+	file, err := s.fileDAO.Get(c.Request.Context(), id, userID)
+	if errors.Is(err, dao.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "file not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		s.logger.Error("failed to get file", zap.String("file_id", id.Hex()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "get file error",
+		})
+		return nil, false
+	}
+
+	return file, true
+}
+
+func fileToJSON(file *dao.File) gin.H {
+	variants := make(gin.H, len(file.Variants))
+	for _, variant := range file.Variants {
+		variants[variant.Name] = gin.H{
+			"filepath":     staticPrefix + variant.Key,
+			"size":         variant.Size,
+			"content_type": variant.ContentType,
+			"width":        variant.Width,
+			"height":       variant.Height,
+		}
+	}
+
+	return gin.H{
+		"id":           file.ID.Hex(),
+		"name":         file.Name,
+		"size":         file.Size,
+		"content_type": file.ContentType,
+		"filepath":     variantPaths(file.Variants)[imagekit.VariantOriginal],
+		"variants":     variants,
+		"created_at":   file.CreatedAT,
+	}
+}
@@ -0,0 +1,299 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/file/dao"
+	"github.com/alice890308/blog-server/pkg/imagekit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/storagekit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// QuarantinePrefix is where the collected files are moved to instead of being deleted, they are not served.
+const QuarantinePrefix = "quarantine/"
+
+type GCConfig struct {
+	Interval    time.Duration `long:"interval" env:"INTERVAL" description:"interval of the garbage collection run by the file server, 0 disables it" default:"0"`
+	GracePeriod time.Duration `long:"grace_period" env:"GRACE_PERIOD" description:"how long a file must stay unreferenced before it is collected" default:"168h"`
+	Quarantine  bool          `long:"quarantine" env:"QUARANTINE" description:"move the collected files under quarantine/ instead of deleting them"`
+}
+
+// filePathPattern finds the paths of stored files in post images, post contents and user avators, relative
+// or inside full URLs.
+var filePathPattern = regexp.MustCompile(`static/([^\s"'()<>\[\]?#]+)`)
+
+// Orphan is a stored object no post, revision or user references.
+type Orphan struct {
+	Key        string
+	Size       int64
+	OrphanedAT time.Time
+}
+
+// GCReport sums up a collection, Collected lists the objects that were removed, or would be in a dry run.
+type GCReport struct {
+	Objects    int
+	Referenced int
+	Pending    []*Orphan
+	Collected  []*Orphan
+	Failed     int
+}
+
+// GarbageCollector removes the uploads that have been referenced by nothing for longer than the grace period,
+// such as the replaced avators and post images. The time an object is first found orphaned is recorded, so
+// that a new upload not yet attached to its post is not collected right away.
+type GarbageCollector struct {
+	storage      storagekit.Storage
+	fileDAO      dao.FileDAO
+	usageDAO     dao.UsageDAO
+	orphanDAO    dao.OrphanDAO
+	referenceDAO dao.ReferenceDAO
+	conf         *GCConfig
+	logger       *logkit.Logger
+}
+
+func NewGarbageCollector(
+	storage storagekit.Storage,
+	fileDAO dao.FileDAO,
+	usageDAO dao.UsageDAO,
+	orphanDAO dao.OrphanDAO,
+	referenceDAO dao.ReferenceDAO,
+	conf *GCConfig,
+	logger *logkit.Logger,
+) *GarbageCollector {
+	return &GarbageCollector{
+		storage:      storage,
+		fileDAO:      fileDAO,
+		usageDAO:     usageDAO,
+		orphanDAO:    orphanDAO,
+		referenceDAO: referenceDAO,
+		conf:         conf,
+		logger:       logger,
+	}
+}
+
+// Run collects the orphaned files every Interval until ctx is done.
+func (g *GarbageCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(g.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report, err := g.Collect(ctx, false)
+			if err != nil {
+				g.logger.Error("failed to collect orphaned files", zap.Error(err))
+				continue
+			}
+			if len(report.Collected) > 0 || report.Failed > 0 {
+				g.logger.Info("collected orphaned files", zap.Int("count", len(report.Collected)), zap.Int("failed", report.Failed))
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Collect removes the objects orphaned for longer than the grace period. A dry run changes nothing, neither
+// the files nor the recorded orphan times, it only reports.
+func (g *GarbageCollector) Collect(ctx context.Context, dryRun bool) (*GCReport, error) {
+	objects, err := g.storage.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// the references are read after the listing, so that a file uploaded and attached in between is seen as referenced
+	referenced := make(map[string]bool)
+	err = g.referenceDAO.Walk(ctx, func(text string) {
+		for _, match := range filePathPattern.FindAllStringSubmatch(text, -1) {
+			referenced[fileBase(match[1])] = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	marks, err := g.orphanDAO.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &GCReport{}
+	var mark, unmark []string
+	exists := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, QuarantinePrefix) {
+			continue
+		}
+		exists[object.Key] = true
+		report.Objects++
+
+		orphanedAT, marked := marks[object.Key]
+		if referenced[fileBase(object.Key)] {
+			report.Referenced++
+			if marked {
+				unmark = append(unmark, object.Key)
+			}
+			continue
+		}
+		if !marked {
+			orphanedAT = now
+			mark = append(mark, object.Key)
+		}
+
+		orphan := &Orphan{Key: object.Key, Size: object.Size, OrphanedAT: orphanedAT}
+		if now.Sub(orphanedAT) >= g.conf.GracePeriod {
+			report.Collected = append(report.Collected, orphan)
+		} else {
+			report.Pending = append(report.Pending, orphan)
+		}
+	}
+	for key := range marks {
+		if !exists[key] {
+			unmark = append(unmark, key)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	// a shutdown stops the collection between two objects, an object being moved to the quarantine is not left
+	// half done and the quotas of the removed objects are still released
+	shutdown := ctx
+	ctx = withoutCancel(ctx)
+
+	if err := g.orphanDAO.Mark(ctx, mark, now); err != nil {
+		return nil, err
+	}
+	if err := g.orphanDAO.Unmark(ctx, unmark); err != nil {
+		return nil, err
+	}
+
+	collected := make([]*Orphan, 0, len(report.Collected))
+	removed := make([]string, 0, len(report.Collected))
+	bases := make(map[string]bool)
+	for _, orphan := range report.Collected {
+		if shutdown.Err() != nil {
+			break
+		}
+
+		if err := g.remove(ctx, orphan.Key); err != nil {
+			g.logger.Error("failed to collect orphaned file", zap.String("key", orphan.Key), zap.Error(err))
+			report.Failed++
+			continue
+		}
+
+		collected = append(collected, orphan)
+		removed = append(removed, orphan.Key)
+		bases[fileBase(orphan.Key)] = true
+	}
+	report.Collected = collected
+
+	if err := g.orphanDAO.Unmark(ctx, removed); err != nil {
+		return nil, err
+	}
+
+	for base := range bases {
+		g.deleteMetadata(ctx, base)
+	}
+
+	return report, nil
+}
+
+// remove deletes the object, or moves it under QuarantinePrefix.
+func (g *GarbageCollector) remove(ctx context.Context, key string) error {
+	if g.conf.Quarantine {
+		body, object, err := g.storage.Get(ctx, key)
+		if errors.Is(err, storagekit.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = g.storage.Put(ctx, QuarantinePrefix+key, body, object.Size, object.ContentType)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return g.storage.Delete(ctx, key)
+}
+
+// deleteMetadata removes the record of a collected upload and gives its size back to the quota of the owner.
+// The files uploaded before the records were kept have none.
+func (g *GarbageCollector) deleteMetadata(ctx context.Context, base string) {
+	owner, name := path.Split(base)
+	userID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(owner, "/"))
+	if err != nil {
+		return
+	}
+	fileID, err := primitive.ObjectIDFromHex(name)
+	if err != nil {
+		return
+	}
+
+	file, err := g.fileDAO.Get(ctx, fileID, userID)
+	if errors.Is(err, dao.ErrFileNotFound) {
+		return
+	}
+	if err != nil {
+		g.logger.Error("failed to get file", zap.String("file_id", fileID.Hex()), zap.Error(err))
+		return
+	}
+
+	// a concurrent delete by the owner has already released the quota
+	err = g.fileDAO.Delete(ctx, fileID, userID)
+	if errors.Is(err, dao.ErrFileNotFound) {
+		return
+	}
+	if err != nil {
+		g.logger.Error("failed to delete file", zap.String("file_id", fileID.Hex()), zap.Error(err))
+		return
+	}
+
+	if err := g.usageDAO.Release(ctx, userID, file.Size); err != nil {
+		g.logger.Error("failed to release storage quota", zap.String("user_id", userID.Hex()), zap.Error(err))
+	}
+}
+
+// fileBase returns the key of the upload an object belongs to, without its extension and variant suffix, so that
+// referencing any variant keeps them all.
+func fileBase(key string) string {
+	base := strings.TrimSuffix(key, path.Ext(key))
+	for _, name := range []string{imagekit.VariantMedium, imagekit.VariantThumbnail} {
+		base = strings.TrimSuffix(base, "_"+name)
+	}
+
+	return base
+}
+
+// uncancelable keeps the values of a context without its cancellation.
+type uncancelable struct {
+	context.Context
+}
+
+func withoutCancel(ctx context.Context) context.Context {
+	return uncancelable{ctx}
+}
+
+func (uncancelable) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (uncancelable) Done() <-chan struct{} {
+	return nil
+}
+
+func (uncancelable) Err() error {
+	return nil
+}
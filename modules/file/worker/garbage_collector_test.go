@@ -0,0 +1,305 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/modules/file/dao"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/alice890308/blog-server/pkg/storagekit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The fakes keep the records in memory and implement the methods the collector uses, calling any other
+// method panics on the nil embedded interface.
+
+type fakeOrphanDAO struct {
+	marks map[string]time.Time
+}
+
+func (f *fakeOrphanDAO) List(ctx context.Context) (map[string]time.Time, error) {
+	marks := make(map[string]time.Time, len(f.marks))
+	for key, orphanedAT := range f.marks {
+		marks[key] = orphanedAT
+	}
+
+	return marks, nil
+}
+
+func (f *fakeOrphanDAO) Mark(ctx context.Context, keys []string, now time.Time) error {
+	for _, key := range keys {
+		if _, ok := f.marks[key]; !ok {
+			f.marks[key] = now
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeOrphanDAO) Unmark(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		delete(f.marks, key)
+	}
+
+	return nil
+}
+
+type fakeReferenceDAO struct {
+	dao.ReferenceDAO
+
+	texts []string
+}
+
+func (f *fakeReferenceDAO) Walk(ctx context.Context, fn func(text string)) error {
+	for _, text := range f.texts {
+		fn(text)
+	}
+
+	return nil
+}
+
+type fakeFileDAO struct {
+	dao.FileDAO
+
+	files map[primitive.ObjectID]*dao.File
+}
+
+func (f *fakeFileDAO) Get(ctx context.Context, id, userID primitive.ObjectID) (*dao.File, error) {
+	file, ok := f.files[id]
+	if !ok || file.UserID != userID {
+		return nil, dao.ErrFileNotFound
+	}
+
+	return file, nil
+}
+
+func (f *fakeFileDAO) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	if _, err := f.Get(ctx, id, userID); err != nil {
+		return err
+	}
+	delete(f.files, id)
+
+	return nil
+}
+
+type fakeUsageDAO struct {
+	dao.UsageDAO
+
+	released map[primitive.ObjectID]int64
+}
+
+func (f *fakeUsageDAO) Release(ctx context.Context, userID primitive.ObjectID, size int64) error {
+	f.released[userID] += size
+
+	return nil
+}
+
+type gcTest struct {
+	gc        *GarbageCollector
+	storage   storagekit.Storage
+	orphanDAO *fakeOrphanDAO
+	fileDAO   *fakeFileDAO
+	usageDAO  *fakeUsageDAO
+
+	userID primitive.ObjectID
+	// the keys of the uploads in every state the collector tells apart
+	referenced, variant, unmarked, pending, expired, stale, quarantined string
+	expiredFileID                                                       primitive.ObjectID
+}
+
+// newGCTest stores an upload referenced through one of its variants, an orphan found for the first time, one
+// within the grace period and one past it, which has a file record, and an object already quarantined. A stale
+// mark of an object that is gone is recorded as well.
+func newGCTest(t *testing.T, conf *GCConfig) *gcTest {
+	t.Helper()
+
+	now := time.Now()
+	tt := &gcTest{
+		storage:       storagekit.NewMemoryStorage("https://files.example"),
+		orphanDAO:     &fakeOrphanDAO{marks: make(map[string]time.Time)},
+		fileDAO:       &fakeFileDAO{files: make(map[primitive.ObjectID]*dao.File)},
+		usageDAO:      &fakeUsageDAO{released: make(map[primitive.ObjectID]int64)},
+		userID:        primitive.NewObjectID(),
+		expiredFileID: primitive.NewObjectID(),
+	}
+	referencedBase := tt.userID.Hex() + "/" + primitive.NewObjectID().Hex()
+	tt.referenced = referencedBase + ".jpg"
+	tt.variant = referencedBase + "_thumbnail.jpg"
+	tt.unmarked = tt.userID.Hex() + "/" + primitive.NewObjectID().Hex() + ".png"
+	tt.pending = tt.userID.Hex() + "/" + primitive.NewObjectID().Hex() + ".png"
+	tt.expired = tt.userID.Hex() + "/" + tt.expiredFileID.Hex() + ".png"
+	tt.stale = tt.userID.Hex() + "/" + primitive.NewObjectID().Hex() + ".png"
+	tt.quarantined = QuarantinePrefix + tt.userID.Hex() + "/" + primitive.NewObjectID().Hex() + ".png"
+
+	for _, key := range []string{tt.referenced, tt.variant, tt.unmarked, tt.pending, tt.expired, tt.quarantined} {
+		if err := tt.storage.Put(context.Background(), key, strings.NewReader("content of "+key), int64(len("content of "+key)), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tt.orphanDAO.marks[tt.referenced] = now.Add(-48 * time.Hour)
+	tt.orphanDAO.marks[tt.pending] = now.Add(-time.Hour)
+	tt.orphanDAO.marks[tt.expired] = now.Add(-48 * time.Hour)
+	tt.orphanDAO.marks[tt.stale] = now.Add(-48 * time.Hour)
+	tt.fileDAO.files[tt.expiredFileID] = &dao.File{ID: tt.expiredFileID, UserID: tt.userID, Size: 300}
+
+	// the post shows the medium variant in a full URL
+	references := &fakeReferenceDAO{texts: []string{`![photo](https://files.example/static/` + referencedBase + `_medium.jpg "photo")`}}
+	tt.gc = NewGarbageCollector(tt.storage, tt.fileDAO, tt.usageDAO, tt.orphanDAO, references, conf, logkit.NewLogger(&logkit.LoggerConfig{}))
+
+	return tt
+}
+
+// keys returns the keys in the storage.
+func (tt *gcTest) keys(t *testing.T) map[string]bool {
+	t.Helper()
+
+	objects, err := tt.storage.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		keys[object.Key] = true
+	}
+
+	return keys
+}
+
+func collectedKeys(report *GCReport) []string {
+	keys := make([]string, 0, len(report.Collected))
+	for _, orphan := range report.Collected {
+		keys = append(keys, orphan.Key)
+	}
+
+	return keys
+}
+
+func TestCollect(t *testing.T) {
+	tt := newGCTest(t, &GCConfig{GracePeriod: 24 * time.Hour})
+
+	report, err := tt.gc.Collect(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected := collectedKeys(report); len(collected) != 1 || collected[0] != tt.expired {
+		t.Errorf("collected %v, want only %s", collected, tt.expired)
+	}
+	if report.Objects != 5 || report.Referenced != 2 || len(report.Pending) != 2 || report.Failed != 0 {
+		t.Errorf("reported %d objects, %d referenced, %d pending and %d failed, want 5, 2, 2 and 0",
+			report.Objects, report.Referenced, len(report.Pending), report.Failed)
+	}
+
+	keys := tt.keys(t)
+	for _, key := range []string{tt.referenced, tt.variant, tt.unmarked, tt.pending, tt.quarantined} {
+		if !keys[key] {
+			t.Errorf("%s was removed", key)
+		}
+	}
+	if keys[tt.expired] {
+		t.Errorf("%s was not removed", tt.expired)
+	}
+
+	// the new orphan starts its grace period, the marks of the referenced, removed and missing objects are dropped
+	for key, want := range map[string]bool{
+		tt.unmarked:   true,
+		tt.pending:    true,
+		tt.referenced: false,
+		tt.expired:    false,
+		tt.stale:      false,
+	} {
+		if _, marked := tt.orphanDAO.marks[key]; marked != want {
+			t.Errorf("%s is marked orphaned: %v, want %v", key, marked, want)
+		}
+	}
+	if orphanedAT := tt.orphanDAO.marks[tt.unmarked]; time.Since(orphanedAT) > time.Minute {
+		t.Errorf("the new orphan was marked at %v, want now", orphanedAT)
+	}
+
+	if _, ok := tt.fileDAO.files[tt.expiredFileID]; ok {
+		t.Error("the record of the collected file was kept")
+	}
+	if released := tt.usageDAO.released[tt.userID]; released != 300 {
+		t.Errorf("released %d bytes of the quota, want 300", released)
+	}
+}
+
+func TestCollectDryRun(t *testing.T) {
+	tt := newGCTest(t, &GCConfig{GracePeriod: 24 * time.Hour})
+	marks, err := tt.orphanDAO.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := tt.keys(t)
+
+	report, err := tt.gc.Collect(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected := collectedKeys(report); len(collected) != 1 || collected[0] != tt.expired {
+		t.Errorf("would collect %v, want only %s", collected, tt.expired)
+	}
+
+	after := tt.keys(t)
+	if len(after) != len(keys) {
+		t.Errorf("the dry run left %d objects of %d", len(after), len(keys))
+	}
+	if len(tt.orphanDAO.marks) != len(marks) {
+		t.Errorf("the dry run left %d marks of %d", len(tt.orphanDAO.marks), len(marks))
+	}
+	for key, orphanedAT := range marks {
+		if !tt.orphanDAO.marks[key].Equal(orphanedAT) {
+			t.Errorf("the dry run changed the mark of %s", key)
+		}
+	}
+	if len(tt.fileDAO.files) != 1 || len(tt.usageDAO.released) != 0 {
+		t.Error("the dry run deleted the file record or released the quota")
+	}
+}
+
+func TestCollectQuarantine(t *testing.T) {
+	tt := newGCTest(t, &GCConfig{GracePeriod: 24 * time.Hour, Quarantine: true})
+
+	if _, err := tt.gc.Collect(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := tt.keys(t)
+	if keys[tt.expired] {
+		t.Errorf("%s was not removed", tt.expired)
+	}
+	body, object, err := tt.storage.Get(context.Background(), QuarantinePrefix+tt.expired)
+	if err != nil {
+		t.Fatalf("%s was not quarantined: %v", tt.expired, err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "content of "+tt.expired || object.ContentType != "image/png" {
+		t.Errorf("quarantined %q as %s, want the content and type of %s", data, object.ContentType, tt.expired)
+	}
+
+	// the quarantine is neither collected nor marked by the next collections
+	tt.orphanDAO.marks[tt.pending] = time.Now().Add(-48 * time.Hour)
+	report, err := tt.gc.Collect(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected := collectedKeys(report); len(collected) != 1 || collected[0] != tt.pending {
+		t.Errorf("collected %v, want only %s", collected, tt.pending)
+	}
+	keys = tt.keys(t)
+	for _, key := range []string{tt.quarantined, QuarantinePrefix + tt.expired, QuarantinePrefix + tt.pending} {
+		if !keys[key] {
+			t.Errorf("%s is not in the quarantine", key)
+		}
+		if _, marked := tt.orphanDAO.marks[key]; marked {
+			t.Errorf("%s in the quarantine is marked orphaned", key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package authkit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessTokenPrefix tells personal access tokens apart from JWTs.
+const AccessTokenPrefix = "pat_"
+
+const accessTokenSize = 32
+
+// Scopes of personal access tokens, a token can only call the methods whose policy names one of its scopes.
+const (
+	ScopePostsRead     = "posts:read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeFilesUpload   = "files:upload"
+)
+
+var scopes = map[string]bool{
+	ScopePostsRead:     true,
+	ScopePostsWrite:    true,
+	ScopeCommentsWrite: true,
+	ScopeFilesUpload:   true,
+}
+
+func ValidScope(scope string) bool {
+	return scopes[scope]
+}
+
+// AccessToken is a personal access token, only the hash of the token is stored.
+type AccessToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id,omitempty"`
+	Name      string             `bson:"name,omitempty"`
+	TokenHash string             `bson:"token_hash,omitempty"`
+	Scopes    []string           `bson:"scopes,omitempty"`
+	// Role is the role of the user, kept up to date when it changes
+	Role Role `bson:"role,omitempty"`
+	// ExpiresAT is zero for tokens that never expire
+	ExpiresAT  time.Time `bson:"expires_at,omitempty"`
+	CreatedAT  time.Time `bson:"created_at,omitempty"`
+	LastUsedAT time.Time `bson:"last_used_at,omitempty"`
+}
+
+type AccessTokenStore interface {
+	Create(ctx context.Context, token *AccessToken) (primitive.ObjectID, error)
+	ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]*AccessToken, error)
+	Delete(ctx context.Context, id, userID primitive.ObjectID) error
+	DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error
+	UpdateRoleByUserID(ctx context.Context, userID primitive.ObjectID, role Role) error
+	// Verify returns the unexpired access token matching token and records that it was used.
+	Verify(ctx context.Context, token string) (*AccessToken, error)
+	CreateIndex(ctx context.Context) error
+}
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+)
+
+// VerifyAccessToken checks a personal access token against the store and returns the payload of its caller.
+func VerifyAccessToken(ctx context.Context, store AccessTokenStore, token string) (*Payload, error) {
+	accessToken, err := store.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrAccessTokenNotFound) {
+			return nil, TokenInvalid
+		}
+		return nil, TokenCheckFailed
+	}
+	if !accessToken.Role.Valid() {
+		return nil, TokenInvalid
+	}
+
+	return &Payload{
+		UserID:        accessToken.UserID.Hex(),
+		Role:          accessToken.Role,
+		AccessTokenID: accessToken.ID.Hex(),
+		Scopes:        accessToken.Scopes,
+	}, nil
+}
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// NewAccessToken returns a random personal access token and its hash.
+func NewAccessToken() (string, string, error) {
+	b := make([]byte, accessTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashAccessToken(token), nil
+}
+
+func HashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
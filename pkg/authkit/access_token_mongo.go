@@ -0,0 +1,146 @@
+package authkit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// accessTokenTouchInterval limits how often the last use of a token is written.
+const accessTokenTouchInterval = time.Minute
+
+type mongoAccessTokenStore struct {
+	collection *mongo.Collection
+}
+
+var _ AccessTokenStore = (*mongoAccessTokenStore)(nil)
+
+func NewMongoAccessTokenStore(collection *mongo.Collection) *mongoAccessTokenStore {
+	return &mongoAccessTokenStore{
+		collection: collection,
+	}
+}
+
+func (s *mongoAccessTokenStore) CreateIndex(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"token_hash", 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{"user_id", 1}, {"created_at", -1}},
+		},
+		{
+			// expired tokens are removed by MongoDB, tokens without expires_at are kept
+			Keys:    bson.D{{"expires_at", 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := s.collection.Indexes().CreateMany(ctx, models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *mongoAccessTokenStore) Create(ctx context.Context, token *AccessToken) (primitive.ObjectID, error) {
+	result, err := s.collection.InsertOne(ctx, token)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	token.ID = result.InsertedID.(primitive.ObjectID)
+
+	return token.ID, nil
+}
+
+func (s *mongoAccessTokenStore) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]*AccessToken, error) {
+	o := options.Find().
+		SetSort(bson.D{{"created_at", -1}}).
+		SetProjection(bson.M{"token_hash": 0})
+
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID}, o)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tokens := make([]*AccessToken, 0)
+	for cursor.Next(ctx) {
+		var token AccessToken
+		if err := cursor.Decode(&token); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, &token)
+	}
+
+	return tokens, nil
+}
+
+func (s *mongoAccessTokenStore) Delete(ctx context.Context, id, userID primitive.ObjectID) error {
+	if result, err := s.collection.DeleteOne(ctx, bson.M{
+		"_id":     id,
+		"user_id": userID,
+	}); err != nil {
+		return err
+	} else if result.DeletedCount == 0 {
+		return ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+func (s *mongoAccessTokenStore) DeleteByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+func (s *mongoAccessTokenStore) UpdateRoleByUserID(ctx context.Context, userID primitive.ObjectID, role Role) error {
+	_, err := s.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set": bson.M{
+				"role": role,
+			},
+		},
+	)
+	return err
+}
+
+func (s *mongoAccessTokenStore) Verify(ctx context.Context, token string) (*AccessToken, error) {
+	now := time.Now()
+
+	var accessToken AccessToken
+	if err := s.collection.FindOne(ctx, bson.M{
+		"token_hash": HashAccessToken(token),
+		// the TTL monitor only runs once a minute
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$gt": now}},
+			bson.M{"expires_at": bson.M{"$exists": false}},
+		},
+	}).Decode(&accessToken); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAccessTokenNotFound
+		}
+		return nil, err
+	}
+
+	if now.Sub(accessToken.LastUsedAT) >= accessTokenTouchInterval {
+		if _, err := s.collection.UpdateByID(ctx, accessToken.ID, bson.M{
+			"$set": bson.M{
+				"last_used_at": now,
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &accessToken, nil
+}
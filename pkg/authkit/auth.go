@@ -3,18 +3,28 @@ package authkit
 import (
 	"context"
 	"log"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
+// identityKeys are the metadata keys set by the interceptor, values sent by the caller are dropped.
+var identityKeys = []string{"user_id", "session_id", "token_id", "role"}
+
 type AuthService struct {
-	JWTManager *JWTManager
+	JWTManager   *JWTManager
+	Denylist     Denylist
+	Policy       Policy
+	AccessTokens AccessTokenStore
 }
 
-func NewAuthService(jwtManager *JWTManager) *AuthService {
+func NewAuthService(jwtManager *JWTManager, denylist Denylist, policy Policy, accessTokens AccessTokenStore) *AuthService {
 	return &AuthService{
-		JWTManager: jwtManager,
+		JWTManager:   jwtManager,
+		Denylist:     denylist,
+		Policy:       policy,
+		AccessTokens: accessTokens,
 	}
 }
 
@@ -28,41 +38,125 @@ func (a *AuthService) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 		// check method if needing authentication
 		log.Printf("{Request: %s}", info.FullMethod)
 
-		if _, ok := publicAPI[info.FullMethod]; ok {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			md = md.Copy()
+			for _, key := range identityKeys {
+				delete(md, key)
+			}
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+
+		rule, ok := a.Policy[info.FullMethod]
+		if !ok {
+			return nil, PermissionDenied
+		}
+
+		if rule.Role == Public {
+			// public APIs still resolve the caller when a valid token is given, personal access tokens
+			// without the scope of the method are served as anonymous callers
+			if newCtx, payload, err := a.authenticate(ctx); err == nil && rule.allows(payload) {
+				return handler(newCtx, req)
+			}
 			return handler(ctx, req)
 		}
 
 		// check token if is valid and get user_id
-		newCtx, err := a.authenticate(ctx)
+		newCtx, payload, err := a.authenticate(ctx)
 		if err != nil {
 			return nil, err
 		}
 
+		if !rule.allows(payload) {
+			return nil, PermissionDenied
+		}
+
 		return handler(newCtx, req)
 	}
 }
 
-func (a *AuthService) authenticate(ctx context.Context) (context.Context, error) {
+// Authorize checks the authorization header of a request served outside of gRPC against a rule, the same way
+// the interceptor checks the methods of the policy.
+func (a *AuthService) Authorize(ctx context.Context, authorization string, rule Rule) (*Payload, error) {
+	if len(authorization) <= len("Bearer ") || !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, TokenNotProvided
+	}
+
+	payload, err := a.verify(ctx, authorization[7:])
+	if err != nil {
+		return nil, err
+	}
+
+	if !rule.allows(payload) {
+		return nil, PermissionDenied
+	}
+
+	return payload, nil
+}
+
+// allows reports whether the caller has the role of the rule, personal access tokens also need its scope.
+func (r Rule) allows(payload *Payload) bool {
+	if !payload.Role.AtLeast(r.Role) {
+		return false
+	}
+
+	// personal access tokens are limited to the methods of their scopes
+	return payload.AccessTokenID == "" || (r.Scope != "" && payload.HasScope(r.Scope))
+}
+
+func (a *AuthService) authenticate(ctx context.Context) (context.Context, *Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return ctx, MetaDataNotProvided
+		return ctx, nil, MetaDataNotProvided
 	}
 
 	values := md["authorization"]
-	if len(values) == 0 {
-		return ctx, TokenNotProvided
+	if len(values) == 0 || len(values[0]) <= len("Bearer ") {
+		return ctx, nil, TokenNotProvided
 	}
 
 	// get access token
-	accessToken := values[0][7:]
+	payload, err := a.verify(ctx, values[0][7:])
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	md = md.Copy()
+	md.Set("user_id", payload.UserID)
+	if payload.AccessTokenID != "" {
+		md.Set("token_id", payload.AccessTokenID)
+	} else {
+		md.Set("session_id", payload.SessionID)
+		md.Set("token_id", payload.Id)
+	}
+	md.Set("role", string(payload.Role))
+	newCtx := metadata.NewIncomingContext(ctx, md)
+
+	return newCtx, payload, nil
+}
+
+// verify accepts a personal access token, or a JWT whose token and session are not revoked.
+func (a *AuthService) verify(ctx context.Context, accessToken string) (*Payload, error) {
+	if IsAccessToken(accessToken) {
+		return VerifyAccessToken(ctx, a.AccessTokens, accessToken)
+	}
+
 	// verify token and get userID
 	payload, err := a.JWTManager.Verify(accessToken)
 	if err != nil {
-		return ctx, TokenInvalid
+		return nil, TokenInvalid
+	}
+	// tokens issued before sessions and roles existed cannot be revoked
+	if payload.Id == "" || payload.SessionID == "" || !payload.Role.Valid() {
+		return nil, TokenInvalid
 	}
 
-	md.Append("user_id", payload.UserID)
-	newCtx := metadata.NewIncomingContext(ctx, md)
+	revoked, err := a.Denylist.IsRevoked(ctx, payload.Id, payload.SessionID)
+	if err != nil {
+		return nil, TokenCheckFailed
+	}
+	if revoked {
+		return nil, TokenRevoked
+	}
 
-	return newCtx, nil
+	return payload, nil
 }
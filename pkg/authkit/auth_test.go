@@ -0,0 +1,191 @@
+package authkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeDenylist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeDenylist) Revoke(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		f.revoked[id] = true
+	}
+
+	return nil
+}
+
+func (f *fakeDenylist) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, id := range ids {
+		if f.revoked[id] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// fakeAccessTokenStore only verifies, calling any other method panics on the nil embedded interface.
+type fakeAccessTokenStore struct {
+	authkit.AccessTokenStore
+
+	tokens map[string]*authkit.AccessToken
+}
+
+func (f *fakeAccessTokenStore) Verify(ctx context.Context, token string) (*authkit.AccessToken, error) {
+	accessToken, ok := f.tokens[token]
+	if !ok {
+		return nil, authkit.ErrAccessTokenNotFound
+	}
+
+	return accessToken, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	jwtManager := authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+		SecretKey:     "secret",
+		Issuer:        "blog-server",
+		Audience:      "blog-server",
+		TokenDuration: 15 * time.Minute,
+	})
+
+	userID := primitive.NewObjectID()
+	generate := func(sessionID string, role authkit.Role) string {
+		token, err := jwtManager.Generate(userID.Hex(), sessionID, role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	reader := generate("session", authkit.RoleReader)
+	revokedSession := generate("revoked-session", authkit.RoleAdmin)
+	challenge, err := jwtManager.GenerateChallenge(userID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &fakeAccessTokenStore{tokens: map[string]*authkit.AccessToken{
+		"pat_files": {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleReader, Scopes: []string{authkit.ScopeFilesUpload}},
+		"pat_posts": {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleAuthor, Scopes: []string{authkit.ScopePostsWrite}},
+	}}
+
+	filesRule := authkit.Rule{Role: authkit.RoleReader, Scope: authkit.ScopeFilesUpload}
+	tests := []struct {
+		name          string
+		authorization string
+		rule          authkit.Rule
+		denylistErr   error
+		want          error
+	}{
+		{name: "jwt", authorization: "Bearer " + reader, rule: filesRule},
+		{name: "access token with the scope", authorization: "Bearer pat_files", rule: filesRule},
+		{name: "no header", rule: filesRule, want: authkit.TokenNotProvided},
+		{name: "not bearer", authorization: "Basic " + reader, rule: filesRule, want: authkit.TokenNotProvided},
+		{name: "malformed jwt", authorization: "Bearer " + reader[:len(reader)-2], rule: filesRule, want: authkit.TokenInvalid},
+		{name: "challenge token", authorization: "Bearer " + challenge, rule: filesRule, want: authkit.TokenInvalid},
+		{name: "revoked session", authorization: "Bearer " + revokedSession, rule: filesRule, want: authkit.TokenRevoked},
+		{name: "denylist down", authorization: "Bearer " + reader, rule: filesRule, denylistErr: errors.New("down"), want: authkit.TokenCheckFailed},
+		{name: "role too low", authorization: "Bearer " + reader, rule: authkit.Rule{Role: authkit.RoleAuthor}, want: authkit.PermissionDenied},
+		{name: "access token without the scope", authorization: "Bearer pat_posts", rule: filesRule, want: authkit.PermissionDenied},
+		{name: "access token on a rule without scope", authorization: "Bearer pat_files", rule: authkit.Rule{Role: authkit.RoleReader}, want: authkit.PermissionDenied},
+		{name: "unknown access token", authorization: "Bearer pat_unknown", rule: filesRule, want: authkit.TokenInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			denylist := &fakeDenylist{revoked: map[string]bool{"revoked-session": true}, err: test.denylistErr}
+			auth := authkit.NewAuthService(jwtManager, denylist, nil, store)
+
+			payload, err := auth.Authorize(context.Background(), test.authorization, test.rule)
+			if err != test.want {
+				t.Fatalf("Authorize returned %v, want %v", err, test.want)
+			}
+			if err == nil && payload.UserID != userID.Hex() {
+				t.Errorf("Authorize returned user %s, want %s", payload.UserID, userID.Hex())
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorPublicMethods(t *testing.T) {
+	logger := logkit.NewLogger(&logkit.LoggerConfig{})
+	jwtManager := authkit.NewJWTManager(logger.WithContext(context.Background()), &authkit.JWTConfig{
+		SecretKey:     "secret",
+		Issuer:        "blog-server",
+		Audience:      "blog-server",
+		TokenDuration: 15 * time.Minute,
+	})
+	policy, err := authkit.NewPolicy(&authkit.PolicyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID := primitive.NewObjectID()
+	jwtToken, err := jwtManager.Generate(userID.Hex(), "session", authkit.RoleReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeAccessTokenStore{tokens: map[string]*authkit.AccessToken{
+		"pat_files": {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleAuthor, Scopes: []string{authkit.ScopeFilesUpload}},
+		"pat_read":  {ID: primitive.NewObjectID(), UserID: userID, Role: authkit.RoleAuthor, Scopes: []string{authkit.ScopePostsRead}},
+	}}
+	interceptor := authkit.NewAuthService(jwtManager, &fakeDenylist{revoked: map[string]bool{}}, policy, store).UnaryServerInterceptor()
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		// caller is the user ID the handler sees, empty for anonymous callers
+		caller string
+	}{
+		{name: "anonymous", method: "/pb.Post/GetPost"},
+		{name: "jwt", method: "/pb.Post/GetPost", authorization: "Bearer " + jwtToken, caller: userID.Hex()},
+		{name: "access token with the scope", method: "/pb.Post/GetPost", authorization: "Bearer pat_read", caller: userID.Hex()},
+		{name: "access token without the scope", method: "/pb.Post/GetPost", authorization: "Bearer pat_files"},
+		{name: "access token without the scope on a listing", method: "/pb.Post/ListPostByUserID", authorization: "Bearer pat_files"},
+		{name: "access token on a method without scope", method: "/pb.Comment/ListCommentsByPost", authorization: "Bearer pat_read"},
+		{name: "invalid token", method: "/pb.Post/GetPost", authorization: "Bearer pat_unknown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a user_id sent by the caller must never reach the handler
+			md := metadata.Pairs("user_id", primitive.NewObjectID().Hex())
+			if test.authorization != "" {
+				md.Set("authorization", test.authorization)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			var caller string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				if values := md.Get("user_id"); len(values) > 0 {
+					caller = values[0]
+				}
+				return nil, nil
+			}
+
+			if _, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler); err != nil {
+				t.Fatal(err)
+			}
+			if caller != test.caller {
+				t.Errorf("handler was called by %q, want %q", caller, test.caller)
+			}
+		})
+	}
+}
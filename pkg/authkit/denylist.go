@@ -0,0 +1,62 @@
+package authkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Denylist keeps the IDs of revoked access tokens and sessions until every access token issued for them has expired.
+type Denylist interface {
+	Revoke(ctx context.Context, ids ...string) error
+	IsRevoked(ctx context.Context, ids ...string) (bool, error)
+}
+
+type redisDenylist struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+var _ Denylist = (*redisDenylist)(nil)
+
+// NewRedisDenylist creates a denylist whose entries live for ttl, which should be the access token duration.
+func NewRedisDenylist(client *redis.Client, ttl time.Duration) *redisDenylist {
+	return &redisDenylist{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func revokedKey(id string) string {
+	return "auth:revoked:" + id
+}
+
+func (d *redisDenylist) Revoke(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.Set(ctx, revokedKey(id), 1, d.ttl)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (d *redisDenylist) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, revokedKey(id))
+	}
+
+	n, err := d.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
@@ -0,0 +1,29 @@
+package authkit
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JWKSPath is where the public keys verifying tokens are published.
+const JWKSPath = "/.well-known/jwks.json"
+
+// jwksMaxAge lets clients cache the keys, a new key must be published for longer than this before it signs tokens.
+const jwksMaxAge = "max-age=300"
+
+// NewJWKSHandler serves the public keys of ks as a JSON Web Key Set.
+func NewJWKSHandler(ks *KeySet) http.Handler {
+	b, _ := json.Marshal(ks.JWKS())
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", jwksMaxAge)
+		_, _ = w.Write(b)
+	})
+}
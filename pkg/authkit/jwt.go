@@ -2,51 +2,173 @@ package authkit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/alice890308/blog-server/pkg/logkit"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type JWT interface {
-	Generate(userID string) (string, error)
+	Generate(userID, sessionID string, role Role) (string, error)
 	Verify(accessToken string) (*Payload, error)
+	GenerateChallenge(userID string) (string, error)
+	VerifyChallenge(challengeToken string) (*Payload, error)
+	GenerateEmailVerification(userID, email string) (string, error)
+	VerifyEmailVerification(verificationToken string) (*Payload, error)
+	TokenDuration() time.Duration
+	RefreshTokenDuration() time.Duration
 }
 
 type JWTConfig struct {
-	SecretKey     string        `long:"secretKey" env:"SECRETKEY" description:"jwt secret key" required:"true"`
-	TokenDuration time.Duration `long:"timeDuration" env:"TIMEDURATION" description:"jwt token duration" default:"60h"`
+	SecretKey            string        `long:"secretKey" env:"SECRETKEY" description:"jwt secret key of HS256 tokens, still accepted without a kid header when a signing key is set"`
+	Issuer               string        `long:"issuer" env:"ISSUER" description:"iss claim of the tokens" default:"blog-server"`
+	Audience             string        `long:"audience" env:"AUDIENCE" description:"aud claim of the tokens" default:"blog-server"`
+	TokenDuration        time.Duration `long:"timeDuration" env:"TIMEDURATION" description:"jwt access token duration" default:"15m"`
+	RefreshTokenDuration time.Duration `long:"refreshDuration" env:"REFRESHDURATION" description:"refresh token duration" default:"720h"`
+	ChallengeDuration    time.Duration `long:"challengeDuration" env:"CHALLENGEDURATION" description:"duration of the login challenge of two-factor authentication" default:"5m"`
+	EmailDuration        time.Duration `long:"emailDuration" env:"EMAILDURATION" description:"duration of the links verifying email addresses" default:"24h"`
+	KeyConfig
 }
 
+const (
+	// purposeLoginChallenge marks the tokens proving the password was right, which only unlock the second factor
+	purposeLoginChallenge = "login_challenge"
+	// purposeEmailVerification marks the tokens of the links proving a user owns an email
+	purposeEmailVerification = "email_verification"
+)
+
+var ErrNoSigningKey = errors.New("no key to sign tokens")
+
 type JWTManager struct {
-	secretKey     string
-	tokenDuration time.Duration
+	secretKey            string
+	keys                 *KeySet
+	issuer               string
+	audience             string
+	tokenDuration        time.Duration
+	refreshTokenDuration time.Duration
+	challengeDuration    time.Duration
+	emailDuration        time.Duration
 }
 
 type Payload struct {
 	jwt.StandardClaims
 	UserID string `json:"user_id"`
+	// SessionID is the refresh token session the access token was issued for
+	SessionID string `json:"sid"`
+	Role      Role   `json:"role"`
+	// Purpose is empty for access tokens
+	Purpose string `json:"purpose,omitempty"`
+	// Email is the verified address of email verification tokens
+	Email string `json:"email,omitempty"`
+	// AccessTokenID and Scopes are only set for personal access tokens, which are not JWTs
+	AccessTokenID string   `json:"-"`
+	Scopes        []string `json:"-"`
+}
+
+func (p *Payload) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
 }
 
 func NewJWTManager(ctx context.Context, conf *JWTConfig) *JWTManager {
-	_ = logkit.FromContext(ctx).With(
+	logger := logkit.FromContext(ctx).With(
 		zap.String("tokenDuration", conf.TokenDuration.String()),
+		zap.String("refreshTokenDuration", conf.RefreshTokenDuration.String()),
 	)
 
+	keys := NewKeySet(ctx, &conf.KeyConfig)
+	if conf.SecretKey == "" && keys.Len() == 0 {
+		logger.Fatal("either a secret key or token keys are required")
+	}
+
 	return &JWTManager{
-		secretKey:     conf.SecretKey,
-		tokenDuration: conf.TokenDuration,
+		secretKey:            conf.SecretKey,
+		keys:                 keys,
+		issuer:               conf.Issuer,
+		audience:             conf.Audience,
+		tokenDuration:        conf.TokenDuration,
+		refreshTokenDuration: conf.RefreshTokenDuration,
+		challengeDuration:    conf.ChallengeDuration,
+		emailDuration:        conf.EmailDuration,
+	}
+}
+
+func (j *JWTManager) TokenDuration() time.Duration {
+	return j.tokenDuration
+}
+
+func (j *JWTManager) RefreshTokenDuration() time.Duration {
+	return j.refreshTokenDuration
+}
+
+func (j *JWTManager) Generate(userID, sessionID string, role Role) (string, error) {
+	now := time.Now()
+	claims := Payload{
+		StandardClaims: j.newStandardClaims(now, j.tokenDuration),
+		UserID:         userID,
+		SessionID:      sessionID,
+		Role:           role,
 	}
+
+	return j.sign(claims)
 }
 
-func (j *JWTManager) Generate(userID string) (string, error) {
+// GenerateChallenge issues the token Login returns instead of an access token when two-factor authentication is on.
+func (j *JWTManager) GenerateChallenge(userID string) (string, error) {
+	now := time.Now()
 	claims := Payload{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(j.tokenDuration).Unix(),
-		},
-		UserID: userID,
+		StandardClaims: j.newStandardClaims(now, j.challengeDuration),
+		UserID:         userID,
+		Purpose:        purposeLoginChallenge,
+	}
+
+	return j.sign(claims)
+}
+
+// GenerateEmailVerification issues the token of the link sent to verify the email of a user,
+// it stops working once the user has another email.
+func (j *JWTManager) GenerateEmailVerification(userID, email string) (string, error) {
+	now := time.Now()
+	claims := Payload{
+		StandardClaims: j.newStandardClaims(now, j.emailDuration),
+		UserID:         userID,
+		Purpose:        purposeEmailVerification,
+		Email:          email,
+	}
+
+	return j.sign(claims)
+}
+
+func (j *JWTManager) newStandardClaims(now time.Time, duration time.Duration) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		Id:        uuid.New().String(),
+		Issuer:    j.issuer,
+		Audience:  j.audience,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(duration).Unix(),
+	}
+}
+
+// sign signs the claims with the signing key, or with the secret key if there is none.
+func (j *JWTManager) sign(claims Payload) (string, error) {
+	if key := j.keys.Signing(); key != nil {
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.PrivateKey)
+	}
+
+	if j.secretKey == "" {
+		return "", ErrNoSigningKey
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -55,17 +177,49 @@ func (j *JWTManager) Generate(userID string) (string, error) {
 
 // Verify verifies the access token string and return a user claim if the token is valid
 func (j *JWTManager) Verify(accessToken string) (*Payload, error) {
+	claims, err := j.parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("not an access token")
+	}
+
+	return claims, nil
+}
+
+func (j *JWTManager) VerifyChallenge(challengeToken string) (*Payload, error) {
+	claims, err := j.parse(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != purposeLoginChallenge {
+		return nil, fmt.Errorf("not a login challenge token")
+	}
+
+	return claims, nil
+}
+
+func (j *JWTManager) VerifyEmailVerification(verificationToken string) (*Payload, error) {
+	claims, err := j.parse(verificationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != purposeEmailVerification || claims.Email == "" {
+		return nil, fmt.Errorf("not an email verification token")
+	}
+
+	return claims, nil
+}
+
+func (j *JWTManager) parse(tokenString string) (*Payload, error) {
 	token, err := jwt.ParseWithClaims(
-		accessToken,
+		tokenString,
 		&Payload{},
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				return nil, fmt.Errorf("unexpected token signing method")
-			}
-
-			return []byte(j.secretKey), nil
-		},
+		j.verificationKey,
 	)
 
 	if err != nil {
@@ -77,5 +231,35 @@ func (j *JWTManager) Verify(accessToken string) (*Payload, error) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	// the expiry, issue time and not before time are checked by the parser
+	if !claims.VerifyIssuer(j.issuer, true) {
+		return nil, fmt.Errorf("invalid token issuer")
+	}
+	if !claims.VerifyAudience(j.audience, true) {
+		return nil, fmt.Errorf("invalid token audience")
+	}
+
 	return claims, nil
 }
+
+// verificationKey picks the key of the kid header, tokens without one are HS256 tokens of the secret key.
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || j.secretKey == "" {
+			return nil, fmt.Errorf("unexpected token signing method")
+		}
+
+		return []byte(j.secretKey), nil
+	}
+
+	key, ok := j.keys.Get(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown token key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected token signing method")
+	}
+
+	return key.PublicKey, nil
+}
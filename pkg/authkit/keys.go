@@ -0,0 +1,315 @@
+package authkit
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"github.com/golang-jwt/jwt"
+	"go.uber.org/zap"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or verifying tokens.
+const minRSAKeyBits = 2048
+
+type KeyConfig struct {
+	SigningKeyFile       string   `long:"signingKeyFile" env:"SIGNINGKEYFILE" description:"PEM file of the RSA or Ed25519 private key signing tokens, tokens are signed with the secret key if empty"`
+	VerificationKeyFiles []string `long:"verificationKeyFile" env:"VERIFICATIONKEYFILES" env-delim:"," description:"PEM files of the public keys still accepted besides the signing key, such as the previous signing key during a rotation"`
+}
+
+// Key is a key of the key set, the private key is only known for the signing key.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PublicKey  crypto.PublicKey
+	PrivateKey crypto.PrivateKey
+}
+
+// KeySet holds the key signing new tokens and every key tokens can be verified with, looked up by the kid header.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	// ordered keeps the keys in the order of the configuration for the JWKS
+	ordered []*Key
+}
+
+func NewKeySet(ctx context.Context, conf *KeyConfig) *KeySet {
+	logger := logkit.FromContext(ctx)
+
+	ks, err := LoadKeySet(conf)
+	if err != nil {
+		logger.Fatal("failed to load token keys", zap.Error(err))
+	}
+
+	for _, key := range ks.ordered {
+		logger.Info("load token key", zap.String("kid", key.ID), zap.String("alg", key.Method.Alg()), zap.Bool("signing", key == ks.signing))
+	}
+
+	return ks
+}
+
+func LoadKeySet(conf *KeyConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+
+	if conf.SigningKeyFile != "" {
+		key, err := loadKey(conf.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.PrivateKey == nil {
+			return nil, fmt.Errorf("signing key file %s does not hold a private key", conf.SigningKeyFile)
+		}
+
+		ks.signing = key
+		ks.add(key)
+	}
+
+	for _, file := range conf.VerificationKeyFiles {
+		key, err := loadKey(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// only the public part of verification keys is kept
+		key.PrivateKey = nil
+		ks.add(key)
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) add(key *Key) {
+	if _, ok := ks.keys[key.ID]; ok {
+		return
+	}
+
+	ks.keys[key.ID] = key
+	ks.ordered = append(ks.ordered, key)
+}
+
+// Signing returns the key signing new tokens, it is nil if no signing key is configured.
+func (ks *KeySet) Signing() *Key {
+	return ks.signing
+}
+
+func (ks *KeySet) Get(kid string) (*Key, bool) {
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) Len() int {
+	return len(ks.ordered)
+}
+
+func loadKey(file string) (*Key, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in key file %s", file)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in key file %s", block.Type, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", file, err)
+	}
+
+	key, err := newKey(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %w", file, err)
+	}
+
+	return key, nil
+}
+
+func newKey(parsed interface{}) (*Key, error) {
+	key := &Key{}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.PrivateKey = k
+		key.PublicKey = &k.PublicKey
+	case *rsa.PublicKey:
+		key.PublicKey = k
+	case ed25519.PrivateKey:
+		key.PrivateKey = k
+		key.PublicKey = k.Public()
+	case ed25519.PublicKey:
+		key.PublicKey = k
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	switch k := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	}
+
+	key.ID = key.JWK().thumbprint()
+
+	return key, nil
+}
+
+// JWK is a public key in the JSON Web Key format of RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	// N and E are set for RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are set for Ed25519 keys, and Y too for EC keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *Key) JWK() JWK {
+	jwk := JWK{
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: k.Method.Alg(),
+	}
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
+
+// thumbprint is the RFC 7638 thumbprint of the key, used as its kid so that every service derives the same one.
+func (jwk JWK) thumbprint() string {
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	// the members are ordered lexicographically and hold no characters json escapes
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// JWKS returns the public keys of the set.
+func (ks *KeySet) JWKS() JWKSet {
+	keys := make([]JWK, 0, len(ks.ordered))
+	for _, key := range ks.ordered {
+		keys = append(keys, key.JWK())
+	}
+
+	return JWKSet{Keys: keys}
+}
+
+// PublicKey decodes the key and returns it with the only signing method it is accepted for.
+func (jwk JWK) PublicKey() (crypto.PublicKey, jwt.SigningMethod, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(e) == 0 || len(e) > 4 {
+			return nil, nil, errors.New("invalid RSA exponent")
+		}
+
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		return key, jwt.SigningMethodRS256, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, nil, errors.New("invalid EC point")
+		}
+		return key, jwt.SigningMethodES256, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
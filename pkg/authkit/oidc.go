@@ -0,0 +1,389 @@
+package authkit
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// oidcLeeway tolerates the clock skew between the provider and us
+	oidcLeeway = time.Minute
+	// oidcKeysRefreshInterval limits how often the keys of the provider are fetched again for an unknown kid
+	oidcKeysRefreshInterval = time.Minute
+	oidcHTTPTimeout         = 10 * time.Second
+	// oidcMaxResponseSize bounds the documents read from the provider
+	oidcMaxResponseSize = 1 << 20
+)
+
+type OIDCConfig struct {
+	IssuerURL    string   `long:"issuerURL" env:"ISSUER_URL" description:"issuer of the OpenID Connect provider, OpenID Connect login is disabled if empty"`
+	ClientID     string   `long:"clientID" env:"CLIENT_ID" description:"client ID registered at the provider"`
+	ClientSecret string   `long:"clientSecret" env:"CLIENT_SECRET" description:"client secret registered at the provider, empty for public clients"`
+	RedirectURL  string   `long:"redirectURL" env:"REDIRECT_URL" description:"URL of the callback of the gateway registered at the provider"`
+	Scopes       []string `long:"scope" env:"SCOPES" env-delim:"," description:"scopes requested from the provider" default:"openid" default:"email" default:"profile"`
+	CompleteURL  string   `long:"completeURL" env:"COMPLETE_URL" description:"frontend page the gateway redirects to with the tokens in the fragment after a login, the tokens are returned as JSON if empty"`
+}
+
+// OIDCProvider runs the authorization code flow with PKCE against an OpenID Connect provider
+// and verifies the ID tokens it issues.
+type OIDCProvider struct {
+	conf   *OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]oidcKey
+	keysAt    time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcKey struct {
+	publicKey crypto.PublicKey
+	method    jwt.SigningMethod
+}
+
+// IDTokenClaims are the claims of an ID token used to identify and create users.
+type IDTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	NotBefore         int64    `json:"nbf,omitempty"`
+	Nonce             string   `json:"nonce,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     bool     `json:"email_verified,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+}
+
+// audience is either a single string or an array in JSON.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+
+	return nil
+}
+
+func (c *IDTokenClaims) Valid() error {
+	now := time.Now()
+
+	if c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(oidcLeeway)) {
+		return errors.New("ID token is expired")
+	}
+	if c.IssuedAt != 0 && now.Add(oidcLeeway).Before(time.Unix(c.IssuedAt, 0)) {
+		return errors.New("ID token is issued in the future")
+	}
+	if c.NotBefore != 0 && now.Add(oidcLeeway).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("ID token is not valid yet")
+	}
+
+	return nil
+}
+
+// NewOIDCProvider returns nil if no issuer is configured. The provider is only contacted once needed.
+func NewOIDCProvider(conf *OIDCConfig) *OIDCProvider {
+	if conf.IssuerURL == "" {
+		return nil
+	}
+
+	return &OIDCProvider{
+		conf:   conf,
+		client: &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// NewPKCE returns a random code verifier and its S256 code challenge.
+func NewPKCE() (string, string, error) {
+	verifier, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// NewOIDCState returns a random value for the state and nonce parameters.
+func NewOIDCState() (string, error) {
+	return randomString()
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL of the provider the user is redirected to for signing in.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.conf.ClientID)
+	q.Set("redirect_uri", p.conf.RedirectURL)
+	q.Set("scope", strings.Join(p.conf.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns the ID token.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.conf.ClientSecret == "" {
+		form.Set("client_id", p.conf.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("no ID token in the token response")
+	}
+
+	return token.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience and lifetime of an ID token of the provider.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken string) (*IDTokenClaims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseWithClaims(rawIDToken, &IDTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.getKey(ctx, d, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected token signing method")
+		}
+
+		return key.publicKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*IDTokenClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid ID token claims")
+	}
+
+	if claims.Issuer != d.Issuer {
+		return nil, fmt.Errorf("invalid ID token issuer")
+	}
+	if !claims.hasAudience(p.conf.ClientID) {
+		return nil, fmt.Errorf("invalid ID token audience")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.conf.ClientID {
+		return nil, fmt.Errorf("invalid ID token authorized party")
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("ID token has no subject")
+	}
+
+	return claims, nil
+}
+
+func (c *IDTokenClaims) hasAudience(clientID string) bool {
+	for _, aud := range c.Audience {
+		if aud == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.conf.IssuerURL, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var d oidcDiscovery
+	if err := p.do(req, &d); err != nil {
+		return nil, fmt.Errorf("failed to discover OpenID Connect provider: %w", err)
+	}
+	// the issuer is compared exactly, so a provider can not impersonate another one
+	if d.Issuer != p.conf.IssuerURL {
+		return nil, fmt.Errorf("provider issuer %q does not match %q", d.Issuer, p.conf.IssuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("incomplete provider metadata")
+	}
+
+	p.discovery = &d
+
+	return p.discovery, nil
+}
+
+// getKey returns the key of kid, the keys are fetched again when kid is unknown since the provider may have rotated them.
+func (p *OIDCProvider) getKey(ctx context.Context, d *oidcDiscovery, kid string) (oidcKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(p.keysAt) < oidcKeysRefreshInterval {
+		return oidcKey{}, fmt.Errorf("unknown ID token key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return oidcKey{}, err
+	}
+
+	var set JWKSet
+	if err := p.do(req, &set); err != nil {
+		return oidcKey{}, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+
+	keys := make(map[string]oidcKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		// keys of unsupported types are skipped, the provider may publish several
+		publicKey, method, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		if jwk.Algorithm != "" && jwk.Algorithm != method.Alg() {
+			continue
+		}
+
+		keys[jwk.KeyID] = oidcKey{publicKey: publicKey, method: method}
+	}
+	p.keys = keys
+	p.keysAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	return oidcKey{}, fmt.Errorf("unknown ID token key %q", kid)
+}
+
+// lookupKey finds the key of kid, tokens without a kid are accepted when the provider has a single key.
+func (p *OIDCProvider) lookupKey(kid string) (oidcKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) do(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.ErrorDescription)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,183 @@
+package authkit_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alice890308/blog-server/pkg/authkit"
+	"github.com/alice890308/blog-server/pkg/authkit/oidctest"
+	"github.com/golang-jwt/jwt"
+)
+
+const testClientID = "blog"
+
+func newTestProvider(iss *oidctest.Issuer) *authkit.OIDCProvider {
+	return authkit.NewOIDCProvider(&authkit.OIDCConfig{
+		IssuerURL:   iss.URL,
+		ClientID:    iss.ClientID,
+		RedirectURL: "https://blog.example/oidc/callback",
+		Scopes:      []string{"openid"},
+	})
+}
+
+func TestOIDCProviderCodeExchange(t *testing.T) {
+	iss := oidctest.NewIssuer(t, testClientID)
+	p := newTestProvider(iss)
+	ctx := context.Background()
+
+	verifier, challenge, err := authkit.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authURL, iss.URL+"/authorize?") || q.Get("state") != "state" || q.Get("nonce") != "nonce" ||
+		q.Get("code_challenge") != challenge || q.Get("code_challenge_method") != "S256" || q.Get("client_id") != testClientID {
+		t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+
+	iss.Grant("code", challenge, iss.Sign(t, iss.Claims("alice", "nonce")))
+
+	if _, err := p.Exchange(ctx, "code", "wrong-verifier"); err == nil {
+		t.Fatal("exchange with a wrong code verifier succeeded")
+	}
+
+	idToken, err := p.Exchange(ctx, "code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := p.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != iss.URL || claims.Subject != "alice" || claims.Nonce != "nonce" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	if _, err := p.Exchange(ctx, "code", verifier); err == nil {
+		t.Fatal("authorization code was redeemed twice")
+	}
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	iss := oidctest.NewIssuer(t, testClientID)
+	p := newTestProvider(iss)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		// sign signs the token instead of the issuer
+		sign  func(jwt.MapClaims) string
+		valid bool
+	}{
+		{
+			name:  "valid",
+			valid: true,
+		},
+		{
+			name:   "audience array",
+			modify: func(c jwt.MapClaims) { c["aud"] = []string{testClientID} },
+			valid:  true,
+		},
+		{
+			name:   "wrong audience",
+			modify: func(c jwt.MapClaims) { c["aud"] = "other" },
+		},
+		{
+			name:   "authorized party of several audiences",
+			modify: func(c jwt.MapClaims) { c["aud"], c["azp"] = []string{testClientID, "other"}, testClientID },
+			valid:  true,
+		},
+		{
+			name:   "wrong authorized party",
+			modify: func(c jwt.MapClaims) { c["aud"], c["azp"] = []string{testClientID, "other"}, "other" },
+		},
+		{
+			name:   "no authorized party of several audiences",
+			modify: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other"} },
+		},
+		{
+			name:   "expired",
+			modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		},
+		{
+			name:   "expired within leeway",
+			modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() },
+			valid:  true,
+		},
+		{
+			name:   "no expiry",
+			modify: func(c jwt.MapClaims) { delete(c, "exp") },
+		},
+		{
+			name:   "issued in the future",
+			modify: func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+		},
+		{
+			name:   "wrong issuer",
+			modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+		},
+		{
+			name:   "no subject",
+			modify: func(c jwt.MapClaims) { delete(c, "sub") },
+		},
+		{
+			name: "wrong key",
+			sign: func(c jwt.MapClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, c)
+				token.Header["kid"] = "test"
+				signed, _ := token.SignedString(otherKey)
+				return signed
+			},
+		},
+		{
+			name: "unsigned",
+			sign: func(c jwt.MapClaims) string {
+				signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				return signed
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := iss.Claims("alice", "nonce")
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+
+			var idToken string
+			if tt.sign != nil {
+				idToken = tt.sign(claims)
+			} else {
+				idToken = iss.Sign(t, claims)
+			}
+
+			_, err := p.VerifyIDToken(context.Background(), idToken)
+			if tt.valid && err != nil {
+				t.Fatalf("valid token rejected: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("invalid token accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package oidctest provides a stub OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const keyID = "test"
+
+// Issuer serves the discovery document, the keys and the token endpoint of a provider. The ID token of an
+// authorization code is returned once, and only for the code verifier of its code challenge.
+type Issuer struct {
+	*httptest.Server
+	ClientID string
+
+	key    ed25519.PrivateKey
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+type grant struct {
+	codeChallenge string
+	idToken       string
+}
+
+// NewIssuer starts an issuer of ID tokens for clientID, it is closed at the end of the test.
+func NewIssuer(t testing.TB, clientID string) *Issuer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iss := &Issuer{
+		ClientID: clientID,
+		key:      key,
+		grants:   make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", iss.discovery)
+	mux.HandleFunc("/jwks", iss.jwks)
+	mux.HandleFunc("/token", iss.token)
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+
+	return iss
+}
+
+// Claims returns the claims of a valid ID token of subject.
+func (iss *Issuer) Claims(subject, nonce string) jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"iss":   iss.URL,
+		"sub":   subject,
+		"aud":   iss.ClientID,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": nonce,
+	}
+}
+
+// Sign returns the ID token of claims signed with the key of the issuer.
+func (iss *Issuer) Sign(t testing.TB, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(iss.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return idToken
+}
+
+// Grant issues the authorization code of a login with codeChallenge, redeemed for idToken.
+func (iss *Issuer) Grant(code, codeChallenge, idToken string) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+
+	iss.grants[code] = grant{codeChallenge: codeChallenge, idToken: idToken}
+}
+
+func (iss *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 iss.URL,
+		"authorization_endpoint": iss.URL + "/authorize",
+		"token_endpoint":         iss.URL + "/token",
+		"jwks_uri":               iss.URL + "/jwks",
+	})
+}
+
+func (iss *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": keyID,
+			"use": "sig",
+			"alg": jwt.SigningMethodEdDSA.Alg(),
+			"x":   base64.RawURLEncoding.EncodeToString(iss.key.Public().(ed25519.PublicKey)),
+		}},
+	})
+}
+
+func (iss *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+
+	code := r.PostFormValue("code")
+	g, ok := iss.grants[code]
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || g.codeChallenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(iss.grants, code)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": g.idToken})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
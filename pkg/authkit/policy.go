@@ -0,0 +1,72 @@
+package authkit
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Public marks the methods that can be called without a token in a policy.
+const Public Role = "public"
+
+type PolicyConfig struct {
+	PolicyFile string `long:"policy_file" env:"POLICY_FILE" description:"path of the YAML file mapping gRPC methods to the least role allowed to call them and their access token scope, the built-in policy is used if empty"`
+}
+
+// Rule is the least role allowed to call a method, and the scope personal access tokens need to call it.
+type Rule struct {
+	Role Role `yaml:"role"`
+	// Scope is empty for methods personal access tokens can not call
+	Scope string `yaml:"scope"`
+}
+
+// UnmarshalYAML accepts a role alone as well as a mapping of role and scope.
+func (r *Rule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var role Role
+	if err := unmarshal(&role); err == nil {
+		*r = Rule{Role: role}
+		return nil
+	}
+
+	type rule Rule
+	return unmarshal((*rule)(r))
+}
+
+// Policy maps full gRPC method names to their rule, methods missing from it are denied.
+type Policy map[string]Rule
+
+//go:embed policy.yaml
+var defaultPolicy []byte
+
+func NewPolicy(conf *PolicyConfig) (Policy, error) {
+	if conf.PolicyFile == "" {
+		return ParsePolicy(defaultPolicy)
+	}
+
+	b, err := os.ReadFile(conf.PolicyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	return ParsePolicy(b)
+}
+
+func ParsePolicy(b []byte) (Policy, error) {
+	var policy Policy
+	if err := yaml.UnmarshalStrict(b, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	for method, rule := range policy {
+		if rule.Role != Public && !rule.Role.Valid() {
+			return nil, fmt.Errorf("unknown role %q for method %s", rule.Role, method)
+		}
+		if rule.Scope != "" && !ValidScope(rule.Scope) {
+			return nil, fmt.Errorf("unknown scope %q for method %s", rule.Scope, method)
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,128 @@
+package imagekit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// withOrientation inserts an APP1 segment with the EXIF orientation tag after the SOI marker of a JPEG image.
+func withOrientation(t *testing.T, data []byte, order binary.ByteOrder, orientation uint16) []byte {
+	t.Helper()
+
+	var tiff bytes.Buffer
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	// the magic number, the offset of the first IFD and its single entry
+	for _, v := range []interface{}{uint16(42), uint32(8), uint16(1), uint16(exifTagOrientation), uint16(exifTypeShort), uint32(1), orientation, uint16(0), uint32(0)} {
+		if err := binary.Write(&tiff, order, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	segment := append(append([]byte{}, exifHeader...), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(data[:2])
+	out.Write([]byte{0xff, jpegMarkerAPP1})
+	if err := binary.Write(&out, binary.BigEndian, uint16(2+len(segment))); err != nil {
+		t.Fatal(err)
+	}
+	out.Write(segment)
+	out.Write(data[2:])
+
+	return out.Bytes()
+}
+
+// halves returns a JPEG image whose left half is red and right half is blue.
+func halves(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	plain := halves(t, 16, 8)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "little endian", data: withOrientation(t, plain, binary.LittleEndian, 6), want: 6},
+		{name: "big endian", data: withOrientation(t, plain, binary.BigEndian, 8), want: 8},
+		{name: "no exif", data: plain, want: 1},
+		{name: "out of range", data: withOrientation(t, plain, binary.LittleEndian, 9), want: 1},
+		{name: "truncated", data: withOrientation(t, plain, binary.LittleEndian, 6)[:20], want: 1},
+		{name: "not a jpeg", data: []byte("\x89PNG\r\n\x1a\n"), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := exifOrientation(test.data); got != test.want {
+				t.Errorf("exifOrientation() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a 3x2 image, the stored top-left pixel is marked to follow where it is displayed
+	const w, h = 3, 2
+	src := image.NewNRGBA(image.Rect(0, 0, w, h))
+	mark := color.NRGBA{R: 255, A: 255}
+	src.SetNRGBA(0, 0, mark)
+
+	tests := []struct {
+		orientation int
+		// wantX and wantY are where the marked pixel is displayed
+		wantX, wantY int
+		swapped      bool
+	}{
+		{orientation: 1, wantX: 0, wantY: 0},
+		{orientation: 2, wantX: w - 1, wantY: 0},
+		{orientation: 3, wantX: w - 1, wantY: h - 1},
+		{orientation: 4, wantX: 0, wantY: h - 1},
+		{orientation: 5, wantX: 0, wantY: 0, swapped: true},
+		{orientation: 6, wantX: h - 1, wantY: 0, swapped: true},
+		{orientation: 7, wantX: h - 1, wantY: w - 1, swapped: true},
+		{orientation: 8, wantX: 0, wantY: w - 1, swapped: true},
+	}
+
+	for _, test := range tests {
+		dst := orient(src, test.orientation)
+
+		wantW, wantH := w, h
+		if test.swapped {
+			wantW, wantH = h, w
+		}
+		if b := dst.Bounds(); b.Dx() != wantW || b.Dy() != wantH {
+			t.Errorf("orientation %d is %dx%d, want %dx%d", test.orientation, b.Dx(), b.Dy(), wantW, wantH)
+			continue
+		}
+		if got := color.NRGBAModel.Convert(dst.At(test.wantX, test.wantY)); got != mark {
+			t.Errorf("orientation %d displays %v at (%d, %d), want the marked pixel", test.orientation, got, test.wantX, test.wantY)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package imagekit
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	VariantOriginal  = "original"
+	VariantMedium    = "medium"
+	VariantThumbnail = "thumbnail"
+
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+type ImageConfig struct {
+	MediumSize    int `long:"medium_size" env:"MEDIUM_SIZE" description:"longest side in pixels of the medium variant" default:"1280"`
+	ThumbnailSize int `long:"thumbnail_size" env:"THUMBNAIL_SIZE" description:"longest side in pixels of the thumbnail variant" default:"320"`
+	JPEGQuality   int `long:"jpeg_quality" env:"JPEG_QUALITY" description:"quality of the re-encoded JPEG images, from 1 to 100" default:"85"`
+	MaxPixels     int `long:"max_pixels" env:"MAX_PIXELS" description:"largest width times height accepted, to bound the memory of decoding" default:"40000000"`
+}
+
+// Variant is an encoded image, stripped of its metadata.
+type Variant struct {
+	Name        string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooManyPixels     = errors.New("image has too many pixels")
+)
+
+// Process decodes a JPEG or PNG image and re-encodes it without metadata as the original variant, then
+// scales it down to the medium and thumbnail variants. A variant is left out when the image already fits
+// in its size. The EXIF orientation is applied to the pixels, since the tag is stripped with the rest.
+func Process(data []byte, conf *ImageConfig) ([]*Variant, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format != FormatJPEG && format != FormatPNG {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width*config.Height > conf.MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatJPEG {
+		img = orient(img, exifOrientation(data))
+	}
+
+	original, err := encode(VariantOriginal, img, format, conf.JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+	variants := []*Variant{original}
+
+	// each variant is scaled from the previous one, which is cheaper than scaling the original again
+	for _, size := range []struct {
+		name string
+		max  int
+	}{
+		{VariantMedium, conf.MediumSize},
+		{VariantThumbnail, conf.ThumbnailSize},
+	} {
+		if !fits(img, size.max) {
+			img = resize(img, size.max)
+
+			variant, err := encode(size.name, img, format, conf.JPEGQuality)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, variant)
+		}
+	}
+
+	return variants, nil
+}
+
+func fits(img image.Image, max int) bool {
+	b := img.Bounds()
+	return max <= 0 || (b.Dx() <= max && b.Dy() <= max)
+}
+
+// resize scales img down so that its longest side is max, keeping the aspect ratio.
+func resize(img image.Image, max int) image.Image {
+	b := img.Bounds()
+	width, height := max, max
+	if b.Dx() > b.Dy() {
+		height = maxInt(1, b.Dy()*max/b.Dx())
+	} else {
+		width = maxInt(1, b.Dx()*max/b.Dy())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	return dst
+}
+
+func encode(name string, img image.Image, format string, quality int) (*Variant, error) {
+	var buf bytes.Buffer
+	var contentType string
+
+	switch format {
+	case FormatJPEG:
+		contentType = "image/jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+	case FormatPNG:
+		contentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	b := img.Bounds()
+	return &Variant{
+		Name:        name,
+		Format:      format,
+		ContentType: contentType,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
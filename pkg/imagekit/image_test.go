@@ -0,0 +1,157 @@
+package imagekit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestProcessVariants(t *testing.T) {
+	conf := &ImageConfig{MediumSize: 40, ThumbnailSize: 10, JPEGQuality: 85, MaxPixels: 1 << 20}
+
+	type size struct {
+		name          string
+		width, height int
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want []size
+	}{
+		{
+			name: "landscape",
+			data: encodePNG(t, 100, 50),
+			want: []size{{VariantOriginal, 100, 50}, {VariantMedium, 40, 20}, {VariantThumbnail, 10, 5}},
+		},
+		{
+			name: "portrait",
+			data: encodePNG(t, 50, 100),
+			want: []size{{VariantOriginal, 50, 100}, {VariantMedium, 20, 40}, {VariantThumbnail, 5, 10}},
+		},
+		{
+			name: "fits the medium size",
+			data: encodePNG(t, 40, 30),
+			want: []size{{VariantOriginal, 40, 30}, {VariantThumbnail, 10, 7}},
+		},
+		{
+			name: "fits the thumbnail size",
+			data: encodePNG(t, 10, 10),
+			want: []size{{VariantOriginal, 10, 10}},
+		},
+		{
+			name: "thin",
+			data: encodePNG(t, 400, 1),
+			want: []size{{VariantOriginal, 400, 1}, {VariantMedium, 40, 1}, {VariantThumbnail, 10, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variants, err := Process(test.data, conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(variants) != len(test.want) {
+				t.Fatalf("returned %d variants, want %d", len(variants), len(test.want))
+			}
+
+			for i, variant := range variants {
+				want := test.want[i]
+				if variant.Name != want.name || variant.Width != want.width || variant.Height != want.height {
+					t.Errorf("variant %d is %s %dx%d, want %s %dx%d", i, variant.Name, variant.Width, variant.Height, want.name, want.width, want.height)
+				}
+				if variant.Format != FormatPNG || variant.ContentType != "image/png" {
+					t.Errorf("variant %s is %s %s, want the format of the upload", variant.Name, variant.Format, variant.ContentType)
+				}
+
+				config, err := png.DecodeConfig(bytes.NewReader(variant.Data))
+				if err != nil {
+					t.Fatalf("variant %s does not decode: %v", variant.Name, err)
+				}
+				if config.Width != variant.Width || config.Height != variant.Height {
+					t.Errorf("variant %s encodes %dx%d, want %dx%d", variant.Name, config.Width, config.Height, variant.Width, variant.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	// the left half, red, is displayed on top when rotated 90° clockwise
+	data := withOrientation(t, halves(t, 32, 16), binary.LittleEndian, 6)
+
+	variants, err := Process(data, &ImageConfig{JPEGQuality: 100, MaxPixels: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := variants[0]
+	if original.Width != 16 || original.Height != 32 {
+		t.Fatalf("the original is %dx%d, want 16x32", original.Width, original.Height)
+	}
+	if bytes.Contains(original.Data, exifHeader) {
+		t.Error("the original keeps its EXIF data")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, at := range []struct {
+		y    int
+		red  bool
+		side string
+	}{
+		{y: 4, red: true, side: "top"},
+		{y: 27, red: false, side: "bottom"},
+	} {
+		r, _, b, _ := img.At(8, at.y).RGBA()
+		if (r > b) != at.red {
+			t.Errorf("the %s is %v, want red: %v", at.side, color.NRGBAModel.Convert(img.At(8, at.y)), at.red)
+		}
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		maxPixels int
+		want      error
+	}{
+		{name: "too many pixels", data: encodePNG(t, 100, 50), maxPixels: 100*50 - 1, want: ErrTooManyPixels},
+		{name: "as many pixels as allowed", data: encodePNG(t, 100, 50), maxPixels: 100 * 50},
+		{name: "unsupported format", data: gifData.Bytes(), maxPixels: 1 << 20, want: ErrUnsupportedFormat},
+		{name: "not an image", data: []byte("not an image"), maxPixels: 1 << 20, want: image.ErrFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Process(test.data, &ImageConfig{JPEGQuality: 85, MaxPixels: test.maxPixels})
+			if !errors.Is(err, test.want) {
+				t.Errorf("Process returned %v, want %v", err, test.want)
+			}
+		})
+	}
+}
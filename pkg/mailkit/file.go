@@ -0,0 +1,47 @@
+package mailkit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes every mail to its own .eml file, it stands in for a mail server in development.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+var _ Mailer = (*fileMailer)(nil)
+
+func NewFileMailer(dir, from string) *fileMailer {
+	return &fileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	b, err := format(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000Z"), hex.EncodeToString(suffix))
+
+	return os.WriteFile(filepath.Join(m.dir, name), b, 0600)
+}
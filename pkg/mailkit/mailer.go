@@ -0,0 +1,71 @@
+package mailkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.uber.org/zap"
+)
+
+type MailerConfig struct {
+	From         string `long:"from" env:"FROM" description:"sender address of the mails" default:"no-reply@localhost"`
+	Dir          string `long:"dir" env:"DIR" description:"directory the mails are written to when no SMTP host is configured" default:"./mails"`
+	SMTPAddr     string `long:"smtp_addr" env:"SMTP_ADDR" description:"host:port of the SMTP server, mails are written to dir if empty"`
+	SMTPUsername string `long:"smtp_username" env:"SMTP_USERNAME" description:"username of the SMTP server"`
+	SMTPPassword string `long:"smtp_password" env:"SMTP_PASSWORD" description:"password of the SMTP server"`
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewMailer sends mails through the configured SMTP server, or writes them to a local directory without one.
+func NewMailer(ctx context.Context, conf *MailerConfig) Mailer {
+	logger := logkit.FromContext(ctx)
+
+	if conf.SMTPAddr != "" {
+		logger.Info("send mails through SMTP", zap.String("smtp_addr", conf.SMTPAddr))
+		return NewSMTPMailer(conf.SMTPAddr, conf.SMTPUsername, conf.SMTPPassword, conf.From)
+	}
+
+	logger.Info("write mails to directory", zap.String("dir", conf.Dir))
+	return NewFileMailer(conf.Dir, conf.From)
+}
+
+var ErrInvalidRecipient = errors.New("invalid recipient")
+
+// format renders msg as an RFC 5322 message.
+func format(from string, msg *Message, now time.Time) ([]byte, error) {
+	// the recipient comes from user data, it must not be able to inject headers
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return nil, ErrInvalidRecipient
+	}
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return nil, ErrInvalidRecipient
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	return b.Bytes(), nil
+}
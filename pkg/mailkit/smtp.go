@@ -0,0 +1,40 @@
+package mailkit
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+var _ Mailer = (*smtpMailer)(nil)
+
+// NewSMTPMailer authenticates with PLAIN auth if username is not empty, net/smtp only allows it over TLS or to localhost.
+func NewSMTPMailer(addr, username, password, from string) *smtpMailer {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailer{
+		addr: addr,
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	b, err := format(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, b)
+}
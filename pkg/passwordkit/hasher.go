@@ -0,0 +1,98 @@
+package passwordkit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+type HasherConfig struct {
+	Algorithm     string `long:"algorithm" env:"ALGORITHM" description:"algorithm hashing new passwords" choice:"bcrypt" choice:"argon2id" default:"bcrypt"`
+	BcryptCost    int    `long:"bcrypt_cost" env:"BCRYPT_COST" description:"cost of bcrypt hashes" default:"12"`
+	Argon2Time    uint32 `long:"argon2_time" env:"ARGON2_TIME" description:"number of passes of argon2id hashes" default:"2"`
+	Argon2Memory  uint32 `long:"argon2_memory" env:"ARGON2_MEMORY" description:"memory of argon2id hashes in KiB" default:"19456"`
+	Argon2Threads uint8  `long:"argon2_threads" env:"ARGON2_THREADS" description:"parallelism of argon2id hashes" default:"1"`
+}
+
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash, and whether hash should be replaced by a new one
+	// because it was made with another algorithm or weaker parameters than the current ones.
+	Verify(hash, password string) (ok bool, needsRehash bool, err error)
+}
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// algorithm is one way to hash passwords, it can tell the hashes it made from their prefix.
+type algorithm interface {
+	Hasher
+	owns(hash string) bool
+}
+
+// hasher hashes with the configured algorithm and verifies the hashes of every known algorithm,
+// so that switching algorithms does not lock anyone out.
+type hasher struct {
+	current    algorithm
+	algorithms []algorithm
+}
+
+var _ Hasher = (*hasher)(nil)
+
+func NewHasher(conf *HasherConfig) (Hasher, error) {
+	bcryptHasher, err := newBcryptHasher(conf.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+	argon2Hasher := newArgon2idHasher(conf.Argon2Time, conf.Argon2Memory, conf.Argon2Threads)
+
+	h := &hasher{
+		algorithms: []algorithm{bcryptHasher, argon2Hasher},
+	}
+
+	switch conf.Algorithm {
+	case AlgorithmBcrypt:
+		h.current = bcryptHasher
+	case AlgorithmArgon2id:
+		h.current = argon2Hasher
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", conf.Algorithm)
+	}
+
+	return h, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *hasher) Verify(hash, password string) (bool, bool, error) {
+	for _, a := range h.algorithms {
+		if !a.owns(hash) {
+			continue
+		}
+
+		ok, needsRehash, err := a.Verify(hash, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+
+		return true, needsRehash || a != h.current, nil
+	}
+
+	return false, false, ErrUnknownHash
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
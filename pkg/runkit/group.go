@@ -0,0 +1,39 @@
+package runkit
+
+import (
+	"context"
+	"sync"
+)
+
+// Group combines several GracefulRunFunc into one. All of them share the same context,
+// the first error cancels the others, and the combined function returns once all of them returned.
+func Group(fns ...GracefulRunFunc) GracefulRunFunc {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var (
+			wg       sync.WaitGroup
+			once     sync.Once
+			firstErr error
+		)
+
+		for _, fn := range fns {
+			wg.Add(1)
+			go func(fn GracefulRunFunc) {
+				defer wg.Done()
+
+				if err := fn(ctx); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}(fn)
+		}
+
+		wg.Wait()
+
+		return firstErr
+	}
+}
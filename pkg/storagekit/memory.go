@@ -0,0 +1,131 @@
+package storagekit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	Object
+	data []byte
+}
+
+// memoryStorage keeps the objects in memory, for development and tests.
+type memoryStorage struct {
+	baseURL string
+
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+}
+
+var _ Storage = (*memoryStorage)(nil)
+
+func NewMemoryStorage(baseURL string) *memoryStorage {
+	return &memoryStorage{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		objects: make(map[string]*memoryObject),
+	}
+}
+
+func (s *memoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = &memoryObject{
+		Object: Object{
+			Key:         key,
+			Size:        int64(len(data)),
+			ContentType: contentType,
+			ModTime:     time.Now(),
+		},
+		data: data,
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, nil, ErrNotExist
+	}
+
+	object := o.Object
+	return io.NopCloser(bytes.NewReader(o.data)), &object, nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+
+	return nil
+}
+
+func (s *memoryStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotExist
+	}
+
+	object := o.Object
+	return &object, nil
+}
+
+func (s *memoryStorage) List(ctx context.Context, prefix string) ([]*Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]*Object, 0)
+	for key, o := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			object := o.Object
+			objects = append(objects, &object)
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	return objects, nil
+}
+
+func (s *memoryStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	return s.baseURL + "/" + key, nil
+}
@@ -0,0 +1,264 @@
+package storagekit
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3MaxSignedURLExpiry is the longest validity S3 accepts for presigned URLs
+	s3MaxSignedURLExpiry = 7 * 24 * time.Hour
+	// s3MaxErrorSize bounds the error documents read from the service
+	s3MaxErrorSize = 64 << 10
+)
+
+// s3Storage talks to S3 or an S3-compatible service such as MinIO over its REST API.
+type s3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    *sigV4Signer
+	client    *http.Client
+}
+
+var _ Storage = (*s3Storage)(nil)
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool) (*s3Storage, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("S3 credentials are required")
+	}
+
+	return &s3Storage{
+		endpoint:  u,
+		bucket:    bucket,
+		pathStyle: pathStyle,
+		signer: &sigV4Signer{
+			accessKey: accessKey,
+			secretKey: secretKey,
+			region:    region,
+		},
+		client: &http.Client{},
+	}, nil
+}
+
+// objectURL addresses key in the bucket, an empty key addresses the bucket.
+func (s *s3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// the request must carry the path encoded as it was signed
+	u.RawPath = uriEncode(u.Path, false)
+
+	return &u
+}
+
+func (s *s3Storage) do(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := emptyPayload
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotExist
+		}
+		return nil, s3Error(resp)
+	}
+
+	return resp, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(key), r, size, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(key), nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp.Body, s3Object(key, resp), nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(key), nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *s3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(key), nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return s3Object(key, resp), nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2, which returns the keys in order. The content type is not listed by S3,
+// it is guessed from the extension.
+func (s *s3Storage) List(ctx context.Context, prefix string) ([]*Object, error) {
+	objects := make([]*Object, 0)
+
+	var continuationToken string
+	for {
+		u := s.objectURL("")
+		q := url.Values{}
+		q.Set("list-type", "2")
+		q.Set("prefix", prefix)
+		if continuationToken != "" {
+			q.Set("continuation-token", continuationToken)
+		}
+		u.RawQuery = canonicalQuery(q)
+
+		resp, err := s.do(ctx, http.MethodGet, u, nil, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, &Object{
+				Key:         content.Key,
+				Size:        content.Size,
+				ContentType: contentTypeOf(content.Key),
+				ModTime:     content.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (s *s3Storage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	if expiry <= 0 || expiry > s3MaxSignedURLExpiry {
+		return "", fmt.Errorf("signed URL expiry must be between 1s and %s", s3MaxSignedURLExpiry)
+	}
+
+	u := s.objectURL(key)
+	s.signer.presign(u, expiry, time.Now())
+
+	return u.String(), nil
+}
+
+func s3Object(key string, resp *http.Response) *Object {
+	object := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		object.Size = size
+	}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModTime = modTime
+	}
+
+	return object
+}
+
+// s3Error reads the code and message of an S3 error document.
+func s3Error(resp *http.Response) error {
+	var doc struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3MaxErrorSize))
+	if err := xml.Unmarshal(body, &doc); err == nil && doc.Code != "" {
+		return fmt.Errorf("s3: %s: %s", doc.Code, strings.TrimSpace(doc.Message))
+	}
+
+	return fmt.Errorf("s3: unexpected status %s", resp.Status)
+}
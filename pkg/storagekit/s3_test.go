@@ -0,0 +1,413 @@
+package storagekit
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBucket    = "uploads"
+	testRegion    = "eu-west-1"
+	testAccessKey = "AKIDTEST"
+	testSecretKey = "secret"
+)
+
+// fakeS3 stands in for a path-style S3 bucket. It rejects the requests whose signature does not match the
+// request as it was received, and lists at most pageSize keys per ListObjectsV2 page.
+type fakeS3 struct {
+	*httptest.Server
+	signer   *sigV4Signer
+	pageSize int
+
+	mu      sync.Mutex
+	objects map[string]*fakeS3Object
+	// continuationTokens records the token of every list request, empty for the first page
+	continuationTokens []string
+}
+
+type fakeS3Object struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+func newFakeS3(t *testing.T, pageSize int) *fakeS3 {
+	t.Helper()
+
+	f := &fakeS3{
+		signer:   &sigV4Signer{accessKey: testAccessKey, secretKey: testSecretKey, region: testRegion},
+		pageSize: pageSize,
+		objects:  make(map[string]*fakeS3Object),
+	}
+	f.Server = httptest.NewServer(f)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeS3) storage(t *testing.T, secretKey string) *s3Storage {
+	t.Helper()
+
+	s, err := NewS3Storage(f.URL, testRegion, testBucket, testAccessKey, secretKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	if r.URL.Query().Get("X-Amz-Signature") != "" {
+		err = f.verifyPresigned(r)
+	} else {
+		err = f.verify(r)
+	}
+	if err != nil {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/"+testBucket+"/") {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+testBucket+"/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody", "The body does not match the Content-Length")
+			return
+		}
+		f.objects[key] = &fakeS3Object{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now()}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.modTime.UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed")
+	}
+}
+
+// verify checks the Authorization header against the request as received, so that a path, query or header
+// sent differently than it was signed is caught.
+func (f *fakeS3) verify(r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, sigV4Algorithm+" ") {
+		return errors.New("missing authorization")
+	}
+
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(auth, sigV4Algorithm+" "), ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if err := f.checkDate(amzDate, fields["Credential"]); err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		if name == "host" {
+			headers[name] = r.Host
+		} else {
+			headers[name] = strings.Join(r.Header.Values(name), ",")
+		}
+	}
+	for _, name := range []string{"host", "x-amz-date", "x-amz-content-sha256"} {
+		if _, ok := headers[name]; !ok {
+			return fmt.Errorf("%s is not signed", name)
+		}
+	}
+	if r.Header.Get("Content-Type") != "" && headers["content-type"] == "" {
+		return errors.New("content-type is not signed")
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(headers)
+	if signedHeaders != fields["SignedHeaders"] {
+		return errors.New("signed headers are not sorted")
+	}
+
+	canonical := canonicalRequest(r.Method, r.URL, canonicalHeaders, signedHeaders, r.Header.Get("X-Amz-Content-Sha256"))
+	signature := f.signer.signature(amzDate, f.signer.scope(amzDate), canonical)
+	if !hmac.Equal([]byte(signature), []byte(fields["Signature"])) {
+		return errors.New("signature does not match")
+	}
+
+	return nil
+}
+
+func (f *fakeS3) verifyPresigned(r *http.Request) error {
+	q := r.URL.Query()
+	if r.Method != http.MethodGet || q.Get("X-Amz-Algorithm") != sigV4Algorithm || q.Get("X-Amz-SignedHeaders") != "host" {
+		return errors.New("invalid presigned request")
+	}
+
+	amzDate := q.Get("X-Amz-Date")
+	if err := f.checkDate(amzDate, q.Get("X-Amz-Credential")); err != nil {
+		return err
+	}
+	date, _ := time.Parse(sigV4Date, amzDate)
+	expires, err := strconv.Atoi(q.Get("X-Amz-Expires"))
+	if err != nil || time.Now().After(date.Add(time.Duration(expires)*time.Second)) {
+		return errors.New("request has expired")
+	}
+
+	signature := q.Get("X-Amz-Signature")
+	q.Del("X-Amz-Signature")
+	u := *r.URL
+	u.RawQuery = q.Encode()
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(map[string]string{"host": r.Host})
+	canonical := canonicalRequest(r.Method, &u, canonicalHeaders, signedHeaders, unsignedPayload)
+	if !hmac.Equal([]byte(f.signer.signature(amzDate, f.signer.scope(amzDate), canonical)), []byte(signature)) {
+		return errors.New("signature does not match")
+	}
+
+	return nil
+}
+
+func (f *fakeS3) checkDate(amzDate, credential string) error {
+	date, err := time.Parse(sigV4Date, amzDate)
+	if err != nil {
+		return errors.New("invalid date")
+	}
+	if d := time.Since(date); d > 15*time.Minute || d < -15*time.Minute {
+		return errors.New("request time is too skewed")
+	}
+	if credential != testAccessKey+"/"+f.signer.scope(amzDate) {
+		return fmt.Errorf("invalid credential %q", credential)
+	}
+
+	return nil
+}
+
+// list serves a ListObjectsV2 page, the continuation token is opaque to the client.
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	token := q.Get("continuation-token")
+	f.continuationTokens = append(f.continuationTokens, token)
+
+	var after string
+	if token != "" {
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect")
+			return
+		}
+		after = string(b)
+	}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, q.Get("prefix")) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key          string
+		Size         int
+		LastModified string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []content
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{}
+	if len(keys) > f.pageSize {
+		keys = keys[:f.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(keys[len(keys)-1]))
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			Size:         len(f.objects[key].data),
+			LastModified: f.objects[key].modTime.UTC().Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func writeS3Error(w http.ResponseWriter, code int, s3Code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", s3Code, message)
+}
+
+func TestS3Storage(t *testing.T) {
+	f := newFakeS3(t, 2)
+
+	testStorage(t, f.storage(t, testSecretKey))
+}
+
+func TestS3StorageListPages(t *testing.T) {
+	f := newFakeS3(t, 2)
+	s := f.storage(t, testSecretKey)
+	ctx := context.Background()
+
+	var keys []string
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("user/%d.png", i)
+		if err := s.Put(ctx, key, strings.NewReader("data"), 4, "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	if err := s.Put(ctx, "other/a.png", strings.NewReader("data"), 4, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	f.continuationTokens = nil
+	checkList(t, s, "user/", keys...)
+
+	want := []string{
+		"",
+		base64.StdEncoding.EncodeToString([]byte("user/1.png")),
+		base64.StdEncoding.EncodeToString([]byte("user/3.png")),
+	}
+	if strings.Join(f.continuationTokens, ",") != strings.Join(want, ",") {
+		t.Errorf("list requests had continuation tokens %q, want %q", f.continuationTokens, want)
+	}
+}
+
+func TestS3StorageRejectedSignature(t *testing.T) {
+	f := newFakeS3(t, 2)
+	s := f.storage(t, "wrong secret")
+
+	err := s.Put(context.Background(), "user/a.png", strings.NewReader("data"), 4, "image/png")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("Put with a wrong secret returned %v, want SignatureDoesNotMatch", err)
+	}
+	if len(f.objects) != 0 {
+		t.Fatal("an unsigned object was stored")
+	}
+}
+
+func TestS3StorageSignedURL(t *testing.T) {
+	f := newFakeS3(t, 2)
+	s := f.storage(t, testSecretKey)
+	ctx := context.Background()
+
+	key := "user/hello world.png"
+	if err := s.Put(ctx, key, strings.NewReader("data"), 4, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	signedURL, err := s.SignedURL(ctx, key, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "data" {
+		t.Fatalf("GET of the signed URL returned %s: %s", resp.Status, body)
+	}
+
+	// the signature only covers the key it was made for
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Path, u.RawPath = "/"+testBucket+"/user/other.png", ""
+	resp, err = http.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("GET of a tampered signed URL returned %s, want 403", resp.Status)
+	}
+
+	for _, expiry := range []time.Duration{0, -time.Minute, s3MaxSignedURLExpiry + time.Second} {
+		if _, err := s.SignedURL(ctx, key, expiry); err == nil {
+			t.Errorf("SignedURL with expiry %s succeeded", expiry)
+		}
+	}
+}
+
+func TestS3StorageObjectURL(t *testing.T) {
+	tests := []struct {
+		pathStyle bool
+		key       string
+		want      string
+	}{
+		{false, "user/a.png", "https://uploads.s3.example.com/user/a.png"},
+		{true, "user/a.png", "https://s3.example.com/uploads/user/a.png"},
+		{true, "user/hello world+ü.png", "https://s3.example.com/uploads/user/hello%20world%2B%C3%BC.png"},
+		{true, "", "https://s3.example.com/uploads/"},
+	}
+
+	for _, tt := range tests {
+		s, err := NewS3Storage("https://s3.example.com", testRegion, testBucket, testAccessKey, testSecretKey, tt.pathStyle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := s.objectURL(tt.key).String(); got != tt.want {
+			t.Errorf("objectURL(%q) with path style %v = %q, want %q", tt.key, tt.pathStyle, got, tt.want)
+		}
+	}
+}
+
+func TestNewS3StorageInvalidConfig(t *testing.T) {
+	tests := []struct {
+		endpoint, bucket, accessKey, secretKey string
+	}{
+		{"s3.example.com", testBucket, testAccessKey, testSecretKey},
+		{"ftp://s3.example.com", testBucket, testAccessKey, testSecretKey},
+		{"https://s3.example.com", "", testAccessKey, testSecretKey},
+		{"https://s3.example.com", testBucket, "", testSecretKey},
+		{"https://s3.example.com", testBucket, testAccessKey, ""},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewS3Storage(tt.endpoint, testRegion, tt.bucket, tt.accessKey, tt.secretKey, true); err == nil {
+			t.Errorf("NewS3Storage(%q, %q, %q, %q) succeeded", tt.endpoint, tt.bucket, tt.accessKey, tt.secretKey)
+		}
+	}
+}
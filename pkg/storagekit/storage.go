@@ -0,0 +1,95 @@
+package storagekit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alice890308/blog-server/pkg/logkit"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLocal  = "local"
+	DriverS3     = "s3"
+	DriverMemory = "memory"
+)
+
+type StorageConfig struct {
+	Driver      string `long:"driver" env:"DRIVER" description:"where files are stored, memory is only meant for development" choice:"local" choice:"s3" choice:"memory" default:"local"`
+	LocalDir    string `long:"local_dir" env:"LOCAL_DIR" description:"directory of the local driver" default:"/static"`
+	LocalURL    string `long:"local_url" env:"LOCAL_URL" description:"URL the files of the local and memory drivers are served under" default:"/static"`
+	S3Endpoint  string `long:"s3_endpoint" env:"S3_ENDPOINT" description:"endpoint of the S3-compatible service" default:"https://s3.amazonaws.com"`
+	S3Region    string `long:"s3_region" env:"S3_REGION" description:"region of the bucket" default:"us-east-1"`
+	S3Bucket    string `long:"s3_bucket" env:"S3_BUCKET" description:"bucket of the S3 driver"`
+	S3AccessKey string `long:"s3_access_key" env:"S3_ACCESS_KEY" description:"access key ID of the S3 driver"`
+	S3SecretKey string `long:"s3_secret_key" env:"S3_SECRET_KEY" description:"secret access key of the S3 driver"`
+	S3PathStyle bool   `long:"s3_path_style" env:"S3_PATH_STYLE" description:"address the bucket in the path instead of the host name, as MinIO expects"`
+}
+
+// Object describes a stored file.
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage stores files under slash separated keys such as <user_id>/<file>.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the content of the object, which the caller must close.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Delete succeeds for objects that do not exist.
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	// List returns the objects whose key starts with prefix, ordered by key.
+	List(ctx context.Context, prefix string) ([]*Object, error)
+	// SignedURL returns a URL downloading the object without credentials until expiry.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+var (
+	ErrNotExist   = errors.New("object does not exist")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+func NewStorage(ctx context.Context, conf *StorageConfig) Storage {
+	logger := logkit.FromContext(ctx).With(zap.String("driver", conf.Driver))
+
+	switch conf.Driver {
+	case DriverLocal:
+		logger.Info("store files in directory", zap.String("dir", conf.LocalDir))
+		return NewLocalStorage(conf.LocalDir, conf.LocalURL)
+	case DriverS3:
+		storage, err := NewS3Storage(conf.S3Endpoint, conf.S3Region, conf.S3Bucket, conf.S3AccessKey, conf.S3SecretKey, conf.S3PathStyle)
+		if err != nil {
+			logger.Fatal("failed to create S3 storage", zap.Error(err))
+		}
+		logger.Info("store files in S3 bucket", zap.String("endpoint", conf.S3Endpoint), zap.String("bucket", conf.S3Bucket))
+		return storage
+	case DriverMemory:
+		logger.Warn("store files in memory, they are lost on restart")
+		return NewMemoryStorage(conf.LocalURL)
+	default:
+		logger.Fatal("unknown storage driver")
+		return nil
+	}
+}
+
+// ValidateKey rejects keys that could escape the storage, such as absolute paths or ones with . or .. segments.
+func ValidateKey(key string) error {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+
+	return nil
+}
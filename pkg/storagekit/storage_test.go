@@ -0,0 +1,232 @@
+package storagekit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{
+		"a.png",
+		"user/file.png",
+		"user/file_medium.jpg",
+		"quarantine/user/file.png",
+		"user/..png",
+		"user/hello world+ü.png",
+	}
+	for _, key := range valid {
+		if err := ValidateKey(key); err != nil {
+			t.Errorf("ValidateKey(%q) = %v, want nil", key, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"/",
+		"/etc/passwd",
+		"../secret",
+		"user/../../secret",
+		"user/..",
+		"./user/file.png",
+		"user/./file.png",
+		"user//file.png",
+		"user/",
+		`..\secret`,
+		`user\file.png`,
+		"user/file\x00.png",
+	}
+	for _, key := range invalid {
+		if err := ValidateKey(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ValidateKey(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "static")
+
+	s := NewLocalStorage(dir, "/static/")
+	testStorage(t, s)
+	checkPublicURL(t, s)
+
+	// nothing was written next to the storage directory
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "static" {
+		t.Fatalf("unexpected files next to the storage directory: %v", entries)
+	}
+}
+
+func TestLocalStorageSkipsPartialUploads(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir, "/static")
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dir, ".upload-123"), []byte("partial"), localFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(ctx, "a.png", failingReader{}, 10, "image/png"); err == nil {
+		t.Fatal("Put of a failing reader succeeded")
+	}
+
+	objects, err := s.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 0 {
+		t.Fatalf("List returned %d objects, want the partial uploads skipped", len(objects))
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage("/static/")
+	testStorage(t, s)
+	checkPublicURL(t, s)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+// testStorage runs the behaviour every driver shares against s, which must be empty.
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	files := map[string]string{
+		"user/b.png":           "png data",
+		"user/a_medium.jpg":    "jpeg data",
+		"other/c.webp":         "webp data",
+		"user/hello world.gif": "gif data",
+	}
+	contentTypes := map[string]string{
+		".png":  "image/png",
+		".jpg":  "image/jpeg",
+		".webp": "image/webp",
+		".gif":  "image/gif",
+	}
+	for key, data := range files {
+		if err := s.Put(ctx, key, strings.NewReader(data), int64(len(data)), contentTypes[filepath.Ext(key)]); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+	}
+
+	// a second put replaces the object
+	if err := s.Put(ctx, "user/b.png", strings.NewReader("new png data"), 12, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	files["user/b.png"] = "new png data"
+
+	for key, data := range files {
+		body, object, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", key, err)
+		}
+		got, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, []byte(data)) {
+			t.Errorf("Get(%q) = %q, want %q", key, got, data)
+		}
+		checkObject(t, object, key, int64(len(data)), contentTypes[filepath.Ext(key)])
+
+		object, err = s.Stat(ctx, key)
+		if err != nil {
+			t.Fatalf("Stat(%q): %v", key, err)
+		}
+		checkObject(t, object, key, int64(len(data)), contentTypes[filepath.Ext(key)])
+	}
+
+	checkList(t, s, "", "other/c.webp", "user/a_medium.jpg", "user/b.png", "user/hello world.gif")
+	checkList(t, s, "user/", "user/a_medium.jpg", "user/b.png", "user/hello world.gif")
+	checkList(t, s, "none/")
+
+	if err := s.Delete(ctx, "user/b.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Get(ctx, "user/b.png"); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Get of a deleted object returned %v, want %v", err, ErrNotExist)
+	}
+	if _, err := s.Stat(ctx, "user/b.png"); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Stat of a deleted object returned %v, want %v", err, ErrNotExist)
+	}
+	if err := s.Delete(ctx, "user/b.png"); err != nil {
+		t.Errorf("Delete of a missing object returned %v", err)
+	}
+	checkList(t, s, "user/", "user/a_medium.jpg", "user/hello world.gif")
+
+	// a key prefix is not an object
+	if _, err := s.Stat(ctx, "user"); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Stat of a prefix returned %v, want %v", err, ErrNotExist)
+	}
+
+	for _, key := range []string{"../escape.png", "user/../../escape.png", "/escape.png", `..\escape.png`} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, "image/png"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if _, _, err := s.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if _, err := s.Stat(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Stat(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if err := s.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+		if _, err := s.SignedURL(ctx, key, time.Hour); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("SignedURL(%q) = %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+}
+
+// checkPublicURL checks that the URLs of s are the public ones under /static, as it can not sign them.
+func checkPublicURL(t *testing.T, s Storage) {
+	t.Helper()
+
+	url, err := s.SignedURL(context.Background(), "user/a_medium.jpg", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "/static/user/a_medium.jpg" {
+		t.Errorf("SignedURL = %q, want /static/user/a_medium.jpg", url)
+	}
+}
+
+func checkObject(t *testing.T, object *Object, key string, size int64, contentType string) {
+	t.Helper()
+
+	if object.Key != key || object.Size != size || object.ContentType != contentType || object.ModTime.IsZero() {
+		t.Errorf("got object %+v, want key %q, size %d and content type %q", object, key, size, contentType)
+	}
+}
+
+func checkList(t *testing.T, s Storage, prefix string, keys ...string) {
+	t.Helper()
+
+	objects, err := s.List(context.Background(), prefix)
+	if err != nil {
+		t.Fatalf("List(%q): %v", prefix, err)
+	}
+
+	got := make([]string, 0, len(objects))
+	for _, object := range objects {
+		got = append(got, object.Key)
+	}
+	if strings.Join(got, ",") != strings.Join(keys, ",") {
+		t.Errorf("List(%q) = %v, want %v", prefix, got, keys)
+	}
+}